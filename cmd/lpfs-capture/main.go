@@ -0,0 +1,266 @@
+// lpfs-capture snapshots the procfs files understood by lpfs into a directory
+// or a tarball, which can later be used as the procfs root of lpfs.NewFS.
+//
+// Usage:
+//
+//	lpfs-capture [-root /proc] -o <dir | file.tar | file.tar.gz>
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// systemFiles are the system-wide procfs files read by lpfs.
+// self/auxv is the auxiliary vector of lpfs-capture itself, which carries the
+// clock ticks and page size of the host.
+var systemFiles = []string{
+	"loadavg",
+	"stat",
+	"meminfo",
+	"swaps",
+	"uptime",
+	"sys/kernel/osrelease",
+	"self/auxv",
+}
+
+// processFiles are the per-process procfs files read by lpfs.
+// environ is left out, as environments often carry secrets, and smaps, as
+// smaps_rollup has its totals at a fraction of the size.
+var processFiles = []string{
+	"stat",
+	"status",
+	"statm",
+	"io",
+	"cmdline",
+	"maps",
+	"smaps_rollup",
+	"limits",
+	"cgroup",
+}
+
+// taskFiles are the per-thread procfs files read by lpfs, in <pid>/task/<tid>.
+var taskFiles = []string{
+	"stat",
+	"status",
+}
+
+// sink receives the captured files.
+type sink interface {
+	WriteFile(name string, data []byte) error
+	Close() error
+}
+
+// dirSink writes the captured files into a directory tree.
+type dirSink struct {
+	dir string
+}
+
+func (d dirSink) WriteFile(name string, data []byte) error {
+	dst := filepath.Join(d.dir, filepath.FromSlash(name))
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, data, 0644)
+}
+
+func (d dirSink) Close() error {
+	return nil
+}
+
+// tarSink writes the captured files into a (optionally gzipped) tarball.
+type tarSink struct {
+	f  *os.File
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarSink(name string, compress bool) (*tarSink, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tarSink{f: f}
+	if compress {
+		t.gz = gzip.NewWriter(f)
+		t.tw = tar.NewWriter(t.gz)
+	} else {
+		t.tw = tar.NewWriter(f)
+	}
+
+	return t, nil
+}
+
+func (t *tarSink) WriteFile(name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+
+	if err := t.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	_, err := t.tw.Write(data)
+	return err
+}
+
+func (t *tarSink) Close() error {
+	err := t.tw.Close()
+	if t.gz != nil {
+		if gzErr := t.gz.Close(); err == nil {
+			err = gzErr
+		}
+	}
+	if fErr := t.f.Close(); err == nil {
+		err = fErr
+	}
+
+	return err
+}
+
+// newSink returns a tarSink if out names a tarball, and a dirSink otherwise.
+func newSink(out string) (sink, error) {
+	switch {
+	case strings.HasSuffix(out, ".tar.gz"), strings.HasSuffix(out, ".tgz"):
+		return newTarSink(out, true)
+	case strings.HasSuffix(out, ".tar"):
+		return newTarSink(out, false)
+	}
+
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return nil, err
+	}
+
+	return dirSink{dir: out}, nil
+}
+
+// capture copies the procfs files read by lpfs from src into dst.
+// System-wide files that do not exist and processes that exit during the
+// capture are skipped. It returns the number of files captured.
+func capture(src fs.FS, dst sink) (int, error) {
+	n := 0
+
+	for _, name := range systemFiles {
+		dat, err := fs.ReadFile(src, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "lpfs-capture: skipping %v: %v\n", name, err)
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+
+		if err := dst.WriteFile(name, dat); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	entries, err := fs.ReadDir(src, ".")
+	if err != nil {
+		return n, err
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := strconv.Atoi(e.Name()); err != nil {
+			continue
+		}
+
+		c, err := captureFiles(src, dst, e.Name(), processFiles)
+		n += c
+		if err != nil {
+			return n, err
+		}
+
+		tasks, err := fs.ReadDir(src, path.Join(e.Name(), "task"))
+		if err != nil {
+			// The process has exited since the directory was read.
+			continue
+		}
+
+		for _, t := range tasks {
+			if _, err := strconv.Atoi(t.Name()); err != nil {
+				continue
+			}
+
+			c, err := captureFiles(src, dst, path.Join(e.Name(), "task", t.Name()), taskFiles)
+			n += c
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// captureFiles copies the files of the process or thread directory dir from
+// src into dst. It returns the number of files captured.
+func captureFiles(src fs.FS, dst sink, dir string, files []string) (int, error) {
+	n := 0
+
+	for _, f := range files {
+		name := path.Join(dir, f)
+
+		dat, err := fs.ReadFile(src, name)
+		if err != nil {
+			// The process has exited since the directory was read,
+			// or the file is private to its owner, e.g. io.
+			continue
+		}
+
+		if err := dst.WriteFile(name, dat); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+func main() {
+	root := flag.String("root", "/proc", "procfs mount point to capture")
+	out := flag.String("o", "", "output directory, or tarball if it ends in .tar, .tar.gz or .tgz")
+	flag.Parse()
+
+	if *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dst, err := newSink(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lpfs-capture: %v\n", err)
+		os.Exit(1)
+	}
+
+	n, err := capture(os.DirFS(*root), dst)
+	if cErr := dst.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lpfs-capture: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("captured %v files from %v into %v\n", n, *root, *out)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rprobaina/lpfs"
+)
+
+var testProcFS = fstest.MapFS{
+	"loadavg":              {Data: []byte("0.50 0.25 1.75 2/613 12345\n")},
+	"uptime":               {Data: []byte("350.50 690.25\n")},
+	"sys/kernel/osrelease": {Data: []byte("6.1.0-13-amd64\n")},
+	"1/stat":               {Data: []byte("1 (systemd) S 0 1 1 0 -1 4194560 46427 3183421 114 1180 122 285 11463 2669 20 0 1 0 27 172404736 3199 18446744073709551615 1 1 0 0 0 0 671173123 4096 1260 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"1/status":             {Data: []byte("Name:\tsystemd\n")},
+	"1/oom_score":          {Data: []byte("0\n")},
+	"1/task/1/stat":        {Data: []byte("1 (systemd) S 0 1 1 0 -1 4194560 46427 3183421 114 1180 122 285 11463 2669 20 0 1 0 27 172404736 3199 18446744073709551615 1 1 0 0 0 0 671173123 4096 1260 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"1/task/1/status":      {Data: []byte("Name:\tsystemd\n")},
+	"1/task/1/comm":        {Data: []byte("systemd\n")},
+	"self/stat":            {Data: []byte("1 (systemd) S\n")},
+	"version":              {Data: []byte("Linux version 6.1.0-13-amd64\n")},
+}
+
+// TestCaptureDir tests that a captured directory can be used as a procfs root.
+func TestCaptureDir(t *testing.T) {
+	dir := t.TempDir()
+
+	n, err := capture(testProcFS, dirSink{dir: dir})
+	if err != nil {
+		t.Fatalf("capture(): %v", err)
+	}
+	if n != 7 {
+		t.Errorf("capture() = %v files; want 7", n)
+	}
+
+	fs, err := lpfs.NewFS(dir)
+	if err != nil {
+		t.Fatalf("NewFS(): %v", err)
+	}
+
+	if l1, err := fs.GetLoadAverage1(); err != nil || l1 != 0.5 {
+		t.Errorf("GetLoadAverage1() = %v, %v; want 0.5", l1, err)
+	}
+
+	if p, err := fs.GetProcessStat(1); err != nil || p.Comm != "systemd" {
+		t.Errorf("GetProcessStat(1) = %+v, %v", p, err)
+	}
+
+	if threads, err := fs.GetProcessThreadsWithStatus(1); err != nil || len(threads) != 1 || threads[0].Status == nil {
+		t.Errorf("GetProcessThreadsWithStatus(1) = %+v, %v", threads, err)
+	}
+
+	for _, name := range []string{"1/oom_score", "1/task/1/comm", "self/stat", "version"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("capture() copied %v", name)
+		}
+	}
+}
+
+// TestCaptureTar tests that a captured tarball contains the procfs files.
+func TestCaptureTar(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "proc.tar.gz")
+
+	dst, err := newSink(out)
+	if err != nil {
+		t.Fatalf("newSink(): %v", err)
+	}
+	if _, err := capture(testProcFS, dst); err != nil {
+		t.Fatalf("capture(): %v", err)
+	}
+	if err := dst.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	got := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		dat, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		got[hdr.Name] = string(dat)
+	}
+
+	for _, name := range []string{"loadavg", "uptime", "sys/kernel/osrelease", "1/stat", "1/status", "1/task/1/stat", "1/task/1/status"} {
+		if got[name] != string(testProcFS[name].Data) {
+			t.Errorf("tarball %v = %q; want %q", name, got[name], testProcFS[name].Data)
+		}
+	}
+	if len(got) != 7 {
+		t.Errorf("tarball has %v files; want 7", len(got))
+	}
+}
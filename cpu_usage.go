@@ -0,0 +1,87 @@
+package lpfs
+
+// CPUPercent contains the percentage of time a CPU spent in each mode between two samples.
+// User and Nice include the time spent running guests, which is not counted twice.
+type CPUPercent struct {
+	ID      int // CPU number, or -1 for the aggregate of all CPUs
+	User    float64
+	Nice    float64
+	System  float64
+	Idle    float64
+	Iowait  float64
+	Irq     float64
+	Softirq float64
+	Steal   float64
+}
+
+// CPUUsageStat contains the CPU utilization between two Stat samples.
+type CPUUsageStat struct {
+	CPU  CPUPercent   // aggregate of all CPUs
+	CPUs []CPUPercent // per-CPU utilization, for the CPUs present in both samples
+}
+
+// CPUUsage returns the CPU utilization between the prev and cur samples of GetStat.
+func CPUUsage(prev, cur Stat) CPUUsageStat {
+	u := CPUUsageStat{CPU: cpuPercent(prev.CPU, cur.CPU)}
+
+	prevCPUs := make(map[int]CPUTimes, len(prev.CPUs))
+	for _, c := range prev.CPUs {
+		prevCPUs[c.ID] = c
+	}
+
+	for _, c := range cur.CPUs {
+		// CPUs may go offline or online between samples.
+		p, ok := prevCPUs[c.ID]
+		if !ok {
+			continue
+		}
+
+		u.CPUs = append(u.CPUs, cpuPercent(p, c))
+	}
+
+	return u
+}
+
+// cpuPercent returns the percentage of time spent in each mode between prev and cur.
+func cpuPercent(prev, cur CPUTimes) CPUPercent {
+	user := delta(prev.User, cur.User)
+	nice := delta(prev.Nice, cur.Nice)
+	system := delta(prev.System, cur.System)
+	idle := delta(prev.Idle, cur.Idle)
+	iowait := delta(prev.Iowait, cur.Iowait)
+	irq := delta(prev.Irq, cur.Irq)
+	softirq := delta(prev.Softirq, cur.Softirq)
+	steal := delta(prev.Steal, cur.Steal)
+
+	// Guest and GuestNice are already accounted in User and Nice.
+	total := user + nice + system + idle + iowait + irq + softirq + steal
+
+	p := CPUPercent{ID: cur.ID}
+	if total == 0 {
+		return p
+	}
+
+	pct := func(v uint64) float64 {
+		return float64(v) * 100 / float64(total)
+	}
+
+	p.User = pct(user)
+	p.Nice = pct(nice)
+	p.System = pct(system)
+	p.Idle = pct(idle)
+	p.Iowait = pct(iowait)
+	p.Irq = pct(irq)
+	p.Softirq = pct(softirq)
+	p.Steal = pct(steal)
+
+	return p
+}
+
+// delta returns cur - prev, or 0 if the counter went backwards (e.g. it was reset).
+func delta(prev, cur uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+
+	return cur - prev
+}
@@ -0,0 +1,55 @@
+package lpfs
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCPUUsage tests the CPU utilization computed from two /proc/stat samples.
+func TestCPUUsage(t *testing.T) {
+	prev := Stat{
+		CPU: CPUTimes{ID: -1, User: 1000, Nice: 100, System: 500, Idle: 8000, Iowait: 100, Irq: 10, Softirq: 20, Steal: 5, Guest: 300, GuestNice: 50},
+		CPUs: []CPUTimes{
+			{ID: 0, User: 600, Nice: 50, System: 250, Idle: 4000, Iowait: 50, Irq: 5, Softirq: 10, Steal: 5, Guest: 200, GuestNice: 50},
+			{ID: 1, User: 400, Nice: 50, System: 250, Idle: 4000, Iowait: 50, Irq: 5, Softirq: 10, Guest: 100},
+		},
+	}
+
+	// 400 ticks elapsed on each CPU; the guest time is part of the user and nice time.
+	// CPU 2 came online between the samples.
+	cur := Stat{
+		CPU: CPUTimes{ID: -1, User: 1400, Nice: 140, System: 580, Idle: 8160, Iowait: 140, Irq: 10, Softirq: 60, Steal: 45, Guest: 540, GuestNice: 90},
+		CPUs: []CPUTimes{
+			{ID: 0, User: 840, Nice: 70, System: 290, Idle: 4040, Iowait: 70, Irq: 5, Softirq: 30, Steal: 25, Guest: 440, GuestNice: 90},
+			{ID: 1, User: 560, Nice: 70, System: 290, Idle: 4120, Iowait: 70, Irq: 5, Softirq: 30, Steal: 20, Guest: 100},
+			{ID: 2, User: 10, Idle: 10},
+		},
+	}
+
+	want := CPUUsageStat{
+		CPU: CPUPercent{ID: -1, User: 50, Nice: 5, System: 10, Idle: 20, Iowait: 5, Irq: 0, Softirq: 5, Steal: 5},
+		CPUs: []CPUPercent{
+			{ID: 0, User: 60, Nice: 5, System: 10, Idle: 10, Iowait: 5, Softirq: 5, Steal: 5},
+			{ID: 1, User: 40, Nice: 5, System: 10, Idle: 30, Iowait: 5, Softirq: 5, Steal: 5},
+		},
+	}
+
+	got := CPUUsage(prev, cur)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CPUUsage() = %+v; want %+v", got, want)
+	}
+
+	// No time elapsed and a counter reset must not produce NaN or negative values.
+	got = CPUUsage(cur, prev)
+	for _, p := range append([]CPUPercent{got.CPU}, got.CPUs...) {
+		for _, v := range []float64{p.User, p.Nice, p.System, p.Idle, p.Iowait, p.Irq, p.Softirq, p.Steal} {
+			if v != v || v < 0 {
+				t.Errorf("CPUUsage() with counters going backwards = %+v", p)
+			}
+		}
+	}
+
+	if got := CPUUsage(cur, cur); got.CPU != (CPUPercent{ID: -1}) {
+		t.Errorf("CPUUsage() of identical samples = %+v; want zeros", got.CPU)
+	}
+}
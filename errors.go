@@ -0,0 +1,105 @@
+package lpfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"syscall"
+)
+
+var (
+	// ErrNotSupported is returned when a procfs file does not exist, e.g.
+	// because the kernel is too old or was built without the feature.
+	ErrNotSupported = errors.New("not supported by the kernel")
+
+	// ErrPermission is returned when a procfs file cannot be read by the
+	// caller, e.g. /proc/<pid>/io of another user's process.
+	ErrPermission = errors.New("permission denied")
+
+	// ErrProcessGone is returned when a process exited before or while its
+	// procfs files were read.
+	ErrProcessGone = errors.New("process has exited")
+
+	// ErrNoSwap is returned by the swap functions when no swap is configured.
+	ErrNoSwap = errors.New("no swap partition")
+)
+
+// ParseError is returned when a procfs file does not have the expected format.
+type ParseError struct {
+	File  string // file relative to the procfs root, e.g. "meminfo" or "1/stat"
+	Field string // field being parsed, if known
+	Line  int    // 1-based line number, or 0 if unknown
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "error parsing %v", e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&b, " line %v", e.Line)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, " field %v", e.Field)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// errUnexpectedFormat is the Err of a ParseError for malformed content.
+func errUnexpectedFormat(content string) error {
+	return fmt.Errorf("unexpected format %q", content)
+}
+
+// readError classifies an error returned while reading a procfs file.
+// It matches both its kind (e.g. ErrProcessGone) and the underlying error
+// (e.g. fs.ErrNotExist) with errors.Is.
+type readError struct {
+	kind error
+	err  error
+}
+
+func (e *readError) Error() string {
+	return fmt.Sprintf("%v: %v", e.kind, e.err)
+}
+
+func (e *readError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *readError) Unwrap() error {
+	return e.err
+}
+
+// classify wraps err, returned while reading name, in a readError.
+// A missing file of a process whose directory is also gone means the
+// process has exited; any other missing file is not supported.
+func (pfs FS) classify(name string, err error) error {
+	var kind error
+
+	switch {
+	case errors.Is(err, syscall.ESRCH):
+		kind = ErrProcessGone
+	case errors.Is(err, fs.ErrPermission):
+		kind = ErrPermission
+	case errors.Is(err, fs.ErrNotExist):
+		kind = ErrNotSupported
+
+		pid := strings.SplitN(name, "/", 2)[0]
+		if isNumeric(pid) && pid != name {
+			if _, statErr := fs.Stat(pfs.fsys, pid); errors.Is(statErr, fs.ErrNotExist) {
+				kind = ErrProcessGone
+			}
+		}
+	default:
+		return err
+	}
+
+	return &readError{kind: kind, err: err}
+}
@@ -0,0 +1,88 @@
+package lpfs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+// deniedFS is a fs.FS that denies access to a given file.
+type deniedFS struct {
+	fs.FS
+	name string
+}
+
+func (d deniedFS) Open(name string) (fs.File, error) {
+	if name == d.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+
+	return d.FS.Open(name)
+}
+
+// TestReadErrors tests the classification of errors returned while reading procfs files.
+func TestReadErrors(t *testing.T) {
+	procfs := NewFSFromFS(deniedFS{FS: vanishedFS{FS: testScanFS, pid: "200"}, name: "1/stat"})
+
+	_, err := procfs.GetProcessStat(200)
+	if !errors.Is(err, ErrProcessGone) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("GetProcessStat() of an exited process = %v; want ErrProcessGone", err)
+	}
+
+	// Process 10 exists, but its kernel does not provide /proc/<pid>/stat.
+	_, err = NewFSFromFS(fstest.MapFS{"10/status": {}}).GetProcessStat(10)
+	if !errors.Is(err, ErrNotSupported) || errors.Is(err, ErrProcessGone) {
+		t.Errorf("GetProcessStat() of a missing file = %v; want ErrNotSupported", err)
+	}
+
+	_, err = procfs.GetProcessStat(1)
+	if !errors.Is(err, ErrPermission) {
+		t.Errorf("GetProcessStat() of a denied file = %v; want ErrPermission", err)
+	}
+
+	_, err = procfs.GetMeminfo()
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("GetMeminfo() of a missing file = %v; want ErrNotSupported", err)
+	}
+
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "meminfo" {
+		t.Errorf("GetMeminfo() of a missing file = %v; want a *fs.PathError", err)
+	}
+}
+
+// TestParseErrors tests that malformed procfs files are reported as a *ParseError.
+func TestParseErrors(t *testing.T) {
+	procfs := NewFSFromFS(fstest.MapFS{
+		"meminfo": {Data: []byte("MemTotal:        6158152 kB\nMemFree:         lots kB\n")},
+		"stat":    {Data: []byte("cpu  1 2 3\nctxt many\n")},
+		"1/stat":  {Data: []byte("1 (systemd) S 0 1 1 0 -1 4194560 46427 3183421 114 1180 x 285\n")},
+		"uptime":  {Data: []byte("\n")},
+	})
+
+	tests := []struct {
+		name string
+		fn   func() error
+		want ParseError
+	}{
+		{"GetMeminfo", func() error { _, err := procfs.GetMeminfo(); return err }, ParseError{File: "meminfo", Field: "MemFree", Line: 2}},
+		{"GetStat", func() error { _, err := procfs.GetStat(); return err }, ParseError{File: "stat", Field: "ctxt", Line: 2}},
+		{"GetProcessStat", func() error { _, err := procfs.GetProcessStat(1); return err }, ParseError{File: "1/stat", Field: "utime"}},
+		{"GetUptimeSystem", func() error { _, err := procfs.GetUptimeSystem(); return err }, ParseError{File: "uptime"}},
+	}
+
+	for _, tt := range tests {
+		err := tt.fn()
+
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%v() = %v; want a *ParseError", tt.name, err)
+			continue
+		}
+
+		if parseErr.File != tt.want.File || parseErr.Field != tt.want.Field || parseErr.Line != tt.want.Line || parseErr.Err == nil {
+			t.Errorf("%v() = %#v; want %#v", tt.name, parseErr, tt.want)
+		}
+	}
+}
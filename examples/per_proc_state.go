@@ -1,3 +1,5 @@
+//go:build ignore
+
 // This is a example of functions that get per-process information.
 // This particular program shows the state of all processes in the system.
 package main
@@ -5,13 +7,15 @@ package main
 import (
 	"fmt"
 	"github.com/rprobaina/lpfs"
+	"os"
 )
 
 func main() {
 	fmt.Println("STAT\tCOMM (PID)")
 	pps, err := lpfs.GetPerProcessStat()
 	if err != nil {
-		fmt.Errorf("Unable to get per-process stat info. Error: %v", err)
+		// Processes that could not be read are reported; the others are still listed.
+		fmt.Fprintf(os.Stderr, "Unable to get per-process stat info. Error: %v\n", err)
 	}
 
 	for _, i := range pps {
@@ -1,3 +1,5 @@
+//go:build ignore
+
 // This is a example of functions that get per-process information.
 // This particular program shows the top Resident Set Size (RSS) memory consumers in the system.
 package main
@@ -5,14 +7,22 @@ package main
 import (
 	"fmt"
 	"github.com/rprobaina/lpfs"
+	"os"
 	"sort"
 )
 
 func main() {
+	u, err := lpfs.GetUnits()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to get units. Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	fmt.Println("RSS (MiB)\tCOMM (PID)")
 	pps, err := lpfs.GetPerProcessStat()
 	if err != nil {
-		fmt.Errorf("Unable to get per-process stat info. Error: %v", err)
+		// Processes that could not be read are reported; the others are still listed.
+		fmt.Fprintf(os.Stderr, "Unable to get per-process stat info. Error: %v\n", err)
 	}
 
 	// Sorting pps slice by top RSS consumers.
@@ -21,6 +31,6 @@ func main() {
 	})
 
 	for _, i := range pps {
-		fmt.Printf("%v\t\t%v (%v)\n", i.Rss, i.Comm, i.Pid)
+		fmt.Printf("%.1f\t\t%v (%v)\n", float64(i.RSSBytes(u))/(1<<20), i.Comm, i.Pid)
 	}
 }
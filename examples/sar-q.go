@@ -1,3 +1,5 @@
+//go:build ignore
+
 // This is a simple example of lpfs usage that simulates the output of `$sar -q`.
 package main
 
@@ -10,14 +12,10 @@ import (
 func main() {
 	fmt.Println("\t\trunq\tplist\t lavg1\tlavg5\tlavg15\tblkd")
 	for {
-		runq, _ := lpfs.GetRunnableQueueSize()
-		plist, _ := lpfs.GetTaskQueueSize()
-		lavg1, _ := lpfs.GetLoadAverage1()
-		lavg5, _ := lpfs.GetLoadAverage5()
-		lavg15, _ := lpfs.GetLoadAverage15()
-		blk := 0 // FIXME
+		l, _ := lpfs.GetLoadavg()
+		s, _ := lpfs.GetStat()
 		t := time.Now().Format("15:04:05")
-		fmt.Printf("%v\t%v\t%v\t %.1f\t%.1f\t%.1f\t%v\n", t, runq, plist, lavg1, lavg5, lavg15, blk)
+		fmt.Printf("%v\t%v\t%v\t %.1f\t%.1f\t%.1f\t%v\n", t, l.Runnable, l.Tasks, l.Load1, l.Load5, l.Load15, s.ProcsBlocked)
 		time.Sleep(time.Second)
 	}
 }
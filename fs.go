@@ -0,0 +1,122 @@
+package lpfs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// FS represents a procfs mount point, e.g. /proc or /host/proc.
+//
+// All procfs access goes through an fs.FS, so a FS can also be backed by
+// in-memory (fstest.MapFS) or embedded (embed.FS) fixtures.
+//
+// The zero FS reads the default /proc, like the package-level functions.
+type FS struct {
+	root string
+	fsys fs.FS
+	auxv *auxvCache
+}
+
+// defaultFS is the FS used by the package-level functions.
+var defaultFS = FS{root: procdir, fsys: os.DirFS(procdir), auxv: &auxvCache{}}
+
+// NewFS returns a FS rooted at the given procfs mount point.
+func NewFS(root string) (FS, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return FS{}, err
+	}
+
+	if !info.IsDir() {
+		return FS{}, fmt.Errorf("%v is not a directory", root)
+	}
+
+	return FS{root: root, fsys: os.DirFS(root), auxv: &auxvCache{}}, nil
+}
+
+// NewFSFromFS returns a FS that reads procfs files from fsys.
+// File names in fsys are relative to the procfs root, e.g. "loadavg" or "1/stat".
+func NewFSFromFS(fsys fs.FS) FS {
+	return FS{fsys: fsys, auxv: &auxvCache{}}
+}
+
+// Root returns the procfs mount point of the FS, or "" if it was created by NewFSFromFS.
+func (pfs FS) Root() string {
+	if pfs.fsys == nil {
+		return defaultFS.root
+	}
+
+	return pfs.root
+}
+
+// readFile reads the file named by elem relative to the procfs root.
+// Errors are classified as ErrProcessGone, ErrPermission or ErrNotSupported.
+func (pfs FS) readFile(elem ...string) ([]byte, error) {
+	if pfs.fsys == nil {
+		return defaultFS.readFile(elem...)
+	}
+
+	name := path.Join(elem...)
+
+	dat, err := fs.ReadFile(pfs.fsys, name)
+	if err != nil {
+		return nil, pfs.classify(name, err)
+	}
+
+	return dat, nil
+}
+
+// readDir reads the directory named by elem relative to the procfs root.
+// Errors are classified as ErrProcessGone, ErrPermission or ErrNotSupported.
+func (pfs FS) readDir(elem ...string) ([]fs.DirEntry, error) {
+	if pfs.fsys == nil {
+		return defaultFS.readDir(elem...)
+	}
+
+	name := path.Join(elem...)
+
+	entries, err := fs.ReadDir(pfs.fsys, name)
+	if err != nil {
+		return nil, pfs.classify(name, err)
+	}
+
+	return entries, nil
+}
+
+// readLinkFS is implemented by file systems that can read symbolic links,
+// e.g. os.DirFS since Go 1.25.
+type readLinkFS interface {
+	ReadLink(name string) (string, error)
+}
+
+// readLink returns the target of the symbolic link named by elem relative to the procfs root.
+// Errors are classified as ErrProcessGone, ErrPermission or ErrNotSupported.
+// Symbolic links can only be read if the FS was created by NewFS, or if its
+// fs.FS has a ReadLink method.
+func (pfs FS) readLink(elem ...string) (string, error) {
+	if pfs.fsys == nil {
+		return defaultFS.readLink(elem...)
+	}
+
+	name := path.Join(elem...)
+
+	var target string
+	var err error
+
+	if pfs.root != "" {
+		target, err = os.Readlink(filepath.Join(pfs.root, filepath.FromSlash(name)))
+	} else if rl, ok := pfs.fsys.(readLinkFS); ok {
+		target, err = rl.ReadLink(name)
+	} else {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: ErrNotSupported}
+	}
+
+	if err != nil {
+		return "", pfs.classify(name, err)
+	}
+
+	return target, nil
+}
@@ -0,0 +1,139 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ProcessIOUsage contains the I/O of a process between two samples of an IOSampler.
+type ProcessIOUsage struct {
+	ProcIO // the current sample
+
+	// New is true if the process was not in the previous sample. The rates
+	// of new processes are zero.
+	New bool
+
+	// Interval is the time elapsed since the previous sample.
+	Interval time.Duration
+
+	ReadRate           float64 // bytes read from storage per second
+	WriteRate          float64 // bytes written to storage per second
+	CancelledWriteRate float64 // bytes of cancelled writeback per second
+	RcharRate          float64 // bytes passed to read system calls per second
+	WcharRate          float64 // bytes passed to write system calls per second
+	SyscrRate          float64 // read system calls per second
+	SyscwRate          float64 // write system calls per second
+}
+
+// IOSampler computes per-process I/O rates from successive samples of
+// /proc/<pid>/io, as shown by iotop. It is not safe for concurrent use.
+type IOSampler struct {
+	pfs  FS
+	opts ScanOptions
+	prev map[int]ProcIO
+	last time.Time
+}
+
+// NewIOSampler returns an IOSampler of the processes in the default /proc.
+func NewIOSampler(opts ScanOptions) *IOSampler {
+	return defaultFS.NewIOSampler(opts)
+}
+
+// NewIOSampler returns an IOSampler of the processes in the FS.
+// opts configures the scan of every sample.
+func (pfs FS) NewIOSampler(opts ScanOptions) *IOSampler {
+	return &IOSampler{pfs: pfs, opts: opts}
+}
+
+// Sample scans the I/O accounting of all processes and returns the I/O of
+// every process since the previous call. On the first call, every process
+// is New.
+//
+// Processes whose I/O accounting the caller may not read are left out
+// without error, so that unprivileged users see their own processes. Other
+// failures are returned in a *ScanError with the processes that were read.
+func (s *IOSampler) Sample() ([]ProcessIOUsage, error) {
+	return s.SampleContext(context.Background())
+}
+
+// SampleContext is like Sample, but stops the scan when ctx is done.
+// In that case, ctx.Err() is returned and the sample is discarded.
+func (s *IOSampler) SampleContext(ctx context.Context) ([]ProcessIOUsage, error) {
+	pio, err := s.pfs.GetPerProcessIOContext(ctx, s.opts)
+
+	var scanErr *ScanError
+	if err != nil && !errors.As(err, &scanErr) {
+		return nil, err
+	}
+
+	if scanErr != nil {
+		err = withoutPermissionErrors(scanErr)
+	}
+
+	return s.Update(pio, time.Now()), err
+}
+
+// withoutPermissionErrors returns scanErr without the errors matching
+// ErrPermission, or nil if there are no others.
+func withoutPermissionErrors(scanErr *ScanError) error {
+	var others []PidError
+
+	for _, e := range scanErr.Errors {
+		if !errors.Is(e.Err, ErrPermission) {
+			others = append(others, e)
+		}
+	}
+
+	if len(others) == 0 {
+		return nil
+	}
+
+	return &ScanError{Errors: others}
+}
+
+// Update records pio, the I/O accounting sampled at t, and returns the I/O
+// of every process since the previous sample. Processes that exited since
+// the previous sample are forgotten.
+//
+// /proc/<pid>/io carries no start time, so a process whose counters went
+// backwards is taken to be a new process that reused the PID.
+func (s *IOSampler) Update(pio []ProcIO, t time.Time) []ProcessIOUsage {
+	interval := t.Sub(s.last)
+	secs := interval.Seconds()
+
+	cur := make(map[int]ProcIO, len(pio))
+	usage := make([]ProcessIOUsage, 0, len(pio))
+
+	for _, p := range pio {
+		cur[p.Pid] = p
+
+		u := ProcessIOUsage{ProcIO: p}
+
+		prev, ok := s.prev[p.Pid]
+		if ok && (p.Rchar < prev.Rchar || p.Wchar < prev.Wchar || p.Syscr < prev.Syscr || p.Syscw < prev.Syscw) {
+			ok = false
+		}
+		if !ok || secs <= 0 {
+			u.New = !ok
+			usage = append(usage, u)
+			continue
+		}
+
+		u.Interval = interval
+		u.ReadRate = float64(delta(prev.ReadBytes, p.ReadBytes)) / secs
+		u.WriteRate = float64(delta(prev.WriteBytes, p.WriteBytes)) / secs
+		u.CancelledWriteRate = float64(delta(prev.CancelledWriteBytes, p.CancelledWriteBytes)) / secs
+		u.RcharRate = float64(delta(prev.Rchar, p.Rchar)) / secs
+		u.WcharRate = float64(delta(prev.Wchar, p.Wchar)) / secs
+		u.SyscrRate = float64(delta(prev.Syscr, p.Syscr)) / secs
+		u.SyscwRate = float64(delta(prev.Syscw, p.Syscw)) / secs
+
+		usage = append(usage, u)
+	}
+
+	s.prev = cur
+	s.last = t
+
+	return usage
+}
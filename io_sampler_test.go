@@ -0,0 +1,92 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestIOSamplerUpdate tests the per-process I/O rates computed from two
+// samples, including started, exited and reused PIDs.
+func TestIOSamplerUpdate(t *testing.T) {
+	s := NewFSFromFS(testIOFS).NewIOSampler(ScanOptions{})
+	t0 := time.Unix(1700000000, 0)
+
+	first := s.Update([]ProcIO{
+		{Pid: 1, Rchar: 1000, Wchar: 2000, Syscr: 10, Syscw: 20, ReadBytes: 4096, WriteBytes: 8192},
+		{Pid: 5, Rchar: 500000, Syscr: 100},
+		{Pid: 9, Rchar: 70},
+	}, t0)
+
+	for _, u := range first {
+		if !u.New || u.ReadRate != 0 {
+			t.Errorf("Update() first sample = %+v; want New with no rates", u)
+		}
+	}
+
+	second := s.Update([]ProcIO{
+		{Pid: 1, Rchar: 3000, Wchar: 2000, Syscr: 14, Syscw: 30, ReadBytes: 4096 + 8192, WriteBytes: 8192 + 4096, CancelledWriteBytes: 4096},
+		{Pid: 5, Rchar: 300, Syscr: 2},
+		{Pid: 7, Rchar: 8},
+	}, t0.Add(2*time.Second))
+
+	if len(second) != 3 {
+		t.Fatalf("Update() = %+v; want 3 processes", second)
+	}
+
+	want := ProcessIOUsage{
+		ProcIO:             ProcIO{Pid: 1, Rchar: 3000, Wchar: 2000, Syscr: 14, Syscw: 30, ReadBytes: 4096 + 8192, WriteBytes: 8192 + 4096, CancelledWriteBytes: 4096},
+		Interval:           2 * time.Second,
+		ReadRate:           4096,
+		WriteRate:          2048,
+		CancelledWriteRate: 2048,
+		RcharRate:          1000,
+		WcharRate:          0,
+		SyscrRate:          2,
+		SyscwRate:          5,
+	}
+	if second[0] != want {
+		t.Errorf("Update() PID 1 = %+v; want %+v", second[0], want)
+	}
+
+	// PID 5 went backwards and was reused by another process, PID 7 is new.
+	for _, u := range second[1:] {
+		if !u.New || u.RcharRate != 0 || u.Interval != 0 {
+			t.Errorf("Update() PID %v = %+v; want New with no rates", u.Pid, u)
+		}
+	}
+
+	// PID 9 exited and is forgotten: it is new if it shows up again.
+	third := s.Update([]ProcIO{{Pid: 9, Rchar: 90}}, t0.Add(3*time.Second))
+	if len(third) != 1 || !third[0].New {
+		t.Errorf("Update() of an exited PID = %+v; want New", third)
+	}
+}
+
+// TestIOSamplerSample tests that sampling leaves out processes that cannot
+// be read by the caller, but reports other failures.
+func TestIOSamplerSample(t *testing.T) {
+	s := newTestIOFS().NewIOSampler(ScanOptions{Workers: 2})
+
+	usage, err := s.Sample()
+
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) || len(scanErr.Errors) != 1 || scanErr.Errors[0].Pid != 100 {
+		t.Errorf("Sample() error = %v; want only PID 100", err)
+	}
+	if len(usage) != 2 || !usage[0].New || usage[0].Pid != 1 {
+		t.Errorf("Sample() = %+v", usage)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if usage, err := s.SampleContext(ctx); usage != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("SampleContext() with a canceled ctx = %+v, %v; want %v", usage, err, context.Canceled)
+	}
+
+	usage, _ = s.Sample()
+	if len(usage) != 2 || usage[0].New || usage[0].Interval <= 0 {
+		t.Errorf("Sample() = %+v", usage)
+	}
+}
@@ -1,25 +1,27 @@
 package lpfs
 
 import (
-	"fmt"
-	"io/ioutil"
-	"os"
+	"context"
+	"path"
 	"strconv"
 	"strings"
 )
 
 const (
 	procdir                  string = "/proc"
-	procdir_loadavg          string = "/proc/loadavg"
-	procdir_swaps            string = "/proc/swaps"
-	procdir_stat             string = "/proc/stat"
-	procdir_uptime           string = "/proc/uptime"
-	procdir_per_process_stat string = "/stat"
-	procdir_meminfo          string = "/proc/meminfo"
-	procdir_osrelease        string = "/proc/sys/kernel/osrelease"
+	procdir_loadavg          string = "loadavg"
+	procdir_swaps            string = "swaps"
+	procdir_stat             string = "stat"
+	procdir_uptime           string = "uptime"
+	procdir_per_process_stat string = "stat"
+	procdir_meminfo          string = "meminfo"
+	procdir_osrelease        string = "sys/kernel/osrelease"
 )
 
-//	Procstat contains process stat available in /proc/<pid>/stat.
+// Procstat contains process stat available in /proc/<pid>/stat.
+//
+// Counters, sizes and addresses are unsigned 64-bit values. Fields that the
+// kernel may report as negative (e.g. Tpgid or Nice) are signed.
 type Procstat struct {
 	Pid                 int
 	Comm                string
@@ -29,999 +31,1171 @@ type Procstat struct {
 	Session             int
 	TtyNr               int
 	Tpgid               int
-	Flags               int
-	Minflt              int
-	Cminflt             int
-	Majflt              int
-	Cmajflt             int
-	Utime               int
-	Stime               int
-	Cutime              int
-	Cstime              int
-	Priority            int
-	Nice                int
-	NumThreads          int
-	Itrealvalue         int
-	Starttime           int
-	Vsize               int
-	Rss                 int
-	Rsslim              string
-	Startcode           int
-	Endcode             int
-	Startstack          int
-	Kstkesp             int
-	Kstkeip             int
-	Signal              int
-	Blocked             int
-	Sigignore           int
-	Sigcatch            int
-	Wchan               int
-	Nswap               int
-	Cnswap              int
+	Flags               uint64
+	Minflt              uint64
+	Cminflt             uint64
+	Majflt              uint64
+	Cmajflt             uint64
+	Utime               uint64
+	Stime               uint64
+	Cutime              uint64
+	Cstime              uint64
+	Priority            int64
+	Nice                int64
+	NumThreads          uint64
+	Itrealvalue         uint64
+	Starttime           uint64
+	Vsize               uint64
+	Rss                 uint64
+	Rsslim              uint64
+	Startcode           uint64
+	Endcode             uint64
+	Startstack          uint64
+	Kstkesp             uint64
+	Kstkeip             uint64
+	Signal              uint64
+	Blocked             uint64
+	Sigignore           uint64
+	Sigcatch            uint64
+	Wchan               uint64
+	Nswap               uint64
+	Cnswap              uint64
 	ExitSignal          int
 	Processor           int
-	RtPriority          int
-	Policy              int
-	DelayacctBlkioTicks int
-	GuestTime           int
-	CguestTime          int
-	StartData           int
-	EndData             int
-	StartBrk            int
-	ArgStart            int
-	ArgEnd              int
-	EnvStart            int
-	EnvEnd              int
+	RtPriority          uint64
+	Policy              uint64
+	DelayacctBlkioTicks uint64
+	GuestTime           uint64
+	CguestTime          uint64
+	StartData           uint64
+	EndData             uint64
+	StartBrk            uint64
+	ArgStart            uint64
+	ArgEnd              uint64
+	EnvStart            uint64
+	EnvEnd              uint64
 	ExitCode            int
+
+	// NumFields is the number of fields emitted by the kernel (52 since
+	// Linux 3.5). Fields beyond NumFields are not available and left zero.
+	NumFields int
+
+	// Cmdline is the command line of the process, as returned by
+	// GetProcessCmdline. It is only set by the per-process scans with
+	// StatScanOptions.Cmdline.
+	Cmdline []string
+
+	// Threads contains the stat of the threads of the process, as returned
+	// by GetProcessThreads. It is only set by the per-process scans with
+	// StatScanOptions.Threads.
+	Threads []Procstat
 }
 
-// GetLoadAverage1 returns the load average over the last minute.
-func GetLoadAverage1() (float64, error) {
-	dat, err := os.ReadFile(procdir_loadavg)
+// Swap contains a swap device entry available in /proc/swaps.
+type Swap struct {
+	Filename string
+	Type     string
+	Size     int // KiB
+	Used     int // KiB
+	Priority int
+}
+
+// CPUTimes contains the amount of time a CPU spent in each mode (USER_HZ).
+// Guest and GuestNice are also accounted in User and Nice.
+type CPUTimes struct {
+	ID        int // CPU number, or -1 for the aggregate of all CPUs
+	User      uint64
+	Nice      uint64
+	System    uint64
+	Idle      uint64
+	Iowait    uint64
+	Irq       uint64
+	Softirq   uint64
+	Steal     uint64
+	Guest     uint64
+	GuestNice uint64
+}
+
+// Stat contains the kernel/system statistics available in /proc/stat.
+type Stat struct {
+	CPU           CPUTimes   // aggregate of all CPUs
+	CPUs          []CPUTimes // per-CPU times, in the order listed by the kernel
+	Intr          uint64     // number of interrupts serviced since boot
+	IntrCounts    []uint64   // number of interrupts serviced per IRQ
+	Ctxt          uint64     // number of context switches since boot
+	Btime         uint64     // boot time, in seconds since the Epoch
+	Processes     uint64     // number of forks since boot
+	ProcsRunning  uint64     // number of runnable tasks
+	ProcsBlocked  uint64     // number of tasks blocked waiting for I/O
+	Softirq       uint64     // number of softirqs serviced since boot
+	SoftirqCounts []uint64   // number of softirqs serviced per softirq type
+}
+
+// Meminfo contains the memory usage information available in /proc/meminfo.
+// Sizes are in bytes, except for the HugePages* fields, which are page counts.
+type Meminfo struct {
+	MemTotal          uint64
+	MemFree           uint64
+	MemAvailable      uint64
+	Buffers           uint64
+	Cached            uint64
+	SwapCached        uint64
+	Active            uint64
+	Inactive          uint64
+	ActiveAnon        uint64
+	InactiveAnon      uint64
+	ActiveFile        uint64
+	InactiveFile      uint64
+	Unevictable       uint64
+	Mlocked           uint64
+	SwapTotal         uint64
+	SwapFree          uint64
+	Zswap             uint64
+	Zswapped          uint64
+	Dirty             uint64
+	Writeback         uint64
+	AnonPages         uint64
+	Mapped            uint64
+	Shmem             uint64
+	KReclaimable      uint64
+	Slab              uint64
+	SReclaimable      uint64
+	SUnreclaim        uint64
+	KernelStack       uint64
+	PageTables        uint64
+	SecPageTables     uint64
+	NFSUnstable       uint64
+	Bounce            uint64
+	WritebackTmp      uint64
+	CommitLimit       uint64
+	CommittedAS       uint64
+	VmallocTotal      uint64
+	VmallocUsed       uint64
+	VmallocChunk      uint64
+	Percpu            uint64
+	HardwareCorrupted uint64
+	AnonHugePages     uint64
+	ShmemHugePages    uint64
+	ShmemPmdMapped    uint64
+	FileHugePages     uint64
+	FilePmdMapped     uint64
+	HugePagesTotal    uint64
+	HugePagesFree     uint64
+	HugePagesRsvd     uint64
+	HugePagesSurp     uint64
+	Hugepagesize      uint64
+	Hugetlb           uint64
+	DirectMap4k       uint64
+	DirectMap2M       uint64
+	DirectMap1G       uint64
+
+	// Other contains the fields not listed above, e.g. those added by newer
+	// kernels. Values with a kB unit are converted to bytes.
+	Other map[string]uint64
+}
+
+// Loadavg contains the system load information available in /proc/loadavg.
+type Loadavg struct {
+	Load1    float64 // load average over the last minute
+	Load5    float64 // load average over the last 5 minutes
+	Load15   float64 // load average over the last 15 minutes
+	Runnable int     // number of currently runnable tasks
+	Tasks    int     // number of existing tasks in the system
+	LastPid  int     // PID of the most recently created process
+}
+
+// GetLoadavg returns the system load information available in /proc/loadavg.
+func GetLoadavg() (Loadavg, error) {
+	return defaultFS.GetLoadavg()
+}
+
+// GetLoadavg returns the system load information available in /proc/loadavg.
+// All fields come from a single read of the file.
+func (pfs FS) GetLoadavg() (Loadavg, error) {
+	dat, err := pfs.readFile(procdir_loadavg)
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_loadavg)
-		return 0.0, err
+		return Loadavg{}, err
+	}
+
+	// e.g. "0.50 0.25 1.75 2/613 12345"
+	dat_s := strings.Fields(string(dat))
+	if len(dat_s) < 5 {
+		return Loadavg{}, &ParseError{File: procdir_loadavg, Err: errUnexpectedFormat(string(dat))}
 	}
 
-	dat_s := strings.Split(string(dat), " ")[0]
+	var l Loadavg
 
-	lavg, err := strconv.ParseFloat(dat_s, 32)
+	l.Load1, err = strconv.ParseFloat(dat_s[0], 64)
 	if err != nil {
-		fmt.Errorf("error parsing %v to float", dat_s)
-		return 0.0, err
+		return Loadavg{}, &ParseError{File: procdir_loadavg, Field: "load1", Err: err}
 	}
 
-	return lavg, nil
-}
+	l.Load5, err = strconv.ParseFloat(dat_s[1], 64)
+	if err != nil {
+		return Loadavg{}, &ParseError{File: procdir_loadavg, Field: "load5", Err: err}
+	}
 
-// GetLoadAverage5 returns the load average over the last 5 minutes.
-func GetLoadAverage5() (float64, error) {
-	dat, err := os.ReadFile(procdir_loadavg)
+	l.Load15, err = strconv.ParseFloat(dat_s[2], 64)
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_loadavg)
-		return 0.0, err
+		return Loadavg{}, &ParseError{File: procdir_loadavg, Field: "load15", Err: err}
 	}
 
-	dat_s := strings.Split(string(dat), " ")[1]
+	tasks := strings.Split(dat_s[3], "/")
+	if len(tasks) != 2 {
+		return Loadavg{}, &ParseError{File: procdir_loadavg, Err: errUnexpectedFormat(string(dat))}
+	}
 
-	lavg, err := strconv.ParseFloat(dat_s, 32)
+	l.Runnable, err = strconv.Atoi(tasks[0])
 	if err != nil {
-		fmt.Errorf("error parsing %v to float", dat_s)
-		return 0.0, err
+		return Loadavg{}, &ParseError{File: procdir_loadavg, Field: "runnable", Err: err}
 	}
 
-	return lavg, nil
-}
+	l.Tasks, err = strconv.Atoi(tasks[1])
+	if err != nil {
+		return Loadavg{}, &ParseError{File: procdir_loadavg, Field: "tasks", Err: err}
+	}
 
-// GetLoadAverage15 returns the load average over the last 15 minutes.
-func GetLoadAverage15() (float64, error) {
-	dat, err := os.ReadFile(procdir_loadavg)
+	l.LastPid, err = strconv.Atoi(dat_s[4])
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_loadavg)
-		return 0.0, err
+		return Loadavg{}, &ParseError{File: procdir_loadavg, Field: "last_pid", Err: err}
 	}
 
-	dat_s := strings.Split(string(dat), " ")[2]
+	return l, nil
+}
 
-	lavg, err := strconv.ParseFloat(dat_s, 32)
+// GetLoadAverage1 returns the load average over the last minute.
+func GetLoadAverage1() (float64, error) {
+	return defaultFS.GetLoadAverage1()
+}
+
+// GetLoadAverage1 returns the load average over the last minute.
+func (pfs FS) GetLoadAverage1() (float64, error) {
+	l, err := pfs.GetLoadavg()
 	if err != nil {
-		fmt.Errorf("error parsing %v to float", dat_s)
-		return 0.0, err
+		return 0, err
 	}
 
-	return lavg, nil
+	return l.Load1, nil
 }
 
-// GetRunnableQueueSize returns the number of currently runnable tasks.
-func GetRunnableQueueSize() (int, error) {
-	dat, err := os.ReadFile(procdir_loadavg)
+// GetLoadAverage5 returns the load average over the last 5 minutes.
+func GetLoadAverage5() (float64, error) {
+	return defaultFS.GetLoadAverage5()
+}
+
+// GetLoadAverage5 returns the load average over the last 5 minutes.
+func (pfs FS) GetLoadAverage5() (float64, error) {
+	l, err := pfs.GetLoadavg()
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_loadavg)
 		return 0, err
 	}
 
-	dat_s := strings.Split(string(dat), " ")[3]
+	return l.Load5, nil
+}
 
-	runq, err := strconv.Atoi(strings.Split(dat_s, "/")[0])
+// GetLoadAverage15 returns the load average over the last 15 minutes.
+func GetLoadAverage15() (float64, error) {
+	return defaultFS.GetLoadAverage15()
+}
+
+// GetLoadAverage15 returns the load average over the last 15 minutes.
+func (pfs FS) GetLoadAverage15() (float64, error) {
+	l, err := pfs.GetLoadavg()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return runq, err
+	return l.Load15, nil
 }
 
-// GetTaskQueueSize returns the number of existing tasks in the system.
-func GetTaskQueueSize() (int, error) {
-	dat, err := os.ReadFile(procdir_loadavg)
+// GetRunnableQueueSize returns the number of currently runnable tasks.
+func GetRunnableQueueSize() (int, error) {
+	return defaultFS.GetRunnableQueueSize()
+}
+
+// GetRunnableQueueSize returns the number of currently runnable tasks.
+func (pfs FS) GetRunnableQueueSize() (int, error) {
+	l, err := pfs.GetLoadavg()
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_loadavg)
 		return 0, err
 	}
 
-	dat_s := strings.Split(string(dat), " ")[3]
+	return l.Runnable, nil
+}
+
+// GetTaskQueueSize returns the number of existing tasks in the system.
+func GetTaskQueueSize() (int, error) {
+	return defaultFS.GetTaskQueueSize()
+}
 
-	tskq, err := strconv.Atoi(strings.Split(dat_s, "/")[1])
+// GetTaskQueueSize returns the number of existing tasks in the system.
+func (pfs FS) GetTaskQueueSize() (int, error) {
+	l, err := pfs.GetLoadavg()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return tskq, err
+	return l.Tasks, nil
 }
 
-// GetMostRecentPid returns the the PID of the process that was most recently created on the system.
+// GetMostRecentPid returns the PID of the process that was most recently created on the system.
 func GetMostRecentPid() (int, error) {
-	dat, err := os.ReadFile(procdir_loadavg)
+	return defaultFS.GetMostRecentPid()
+}
+
+// GetMostRecentPid returns the PID of the process that was most recently created on the system.
+func (pfs FS) GetMostRecentPid() (int, error) {
+	l, err := pfs.GetLoadavg()
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_loadavg)
 		return 0, err
 	}
 
-	dat_s := strings.Split(string(dat), " ")[4]
+	return l.LastPid, nil
+}
+
+// GetSwaps returns every swap device available in /proc/swaps.
+func GetSwaps() ([]Swap, error) {
+	return defaultFS.GetSwaps()
+}
 
-	pid, err := strconv.Atoi(dat_s[:len(dat_s)-1])
+// GetSwaps returns every swap device available in /proc/swaps.
+// It returns ErrNoSwap if no swap is configured.
+func (pfs FS) GetSwaps() ([]Swap, error) {
+	dat, err := pfs.readFile(procdir_swaps)
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return 0, err
+		return nil, err
 	}
 
-	return pid, nil
+	var swaps []Swap
+
+	// Skipping the "Filename Type Size Used Priority" header.
+	for i, line := range strings.Split(string(dat), "\n")[1:] {
+		dat_s := strings.Fields(line)
+		if len(dat_s) == 0 {
+			continue
+		}
+		if len(dat_s) < 5 {
+			return nil, &ParseError{File: procdir_swaps, Line: i + 2, Err: errUnexpectedFormat(line)}
+		}
+
+		s := Swap{Filename: dat_s[0], Type: dat_s[1]}
+
+		s.Size, err = strconv.Atoi(dat_s[2])
+		if err != nil {
+			return nil, &ParseError{File: procdir_swaps, Field: "size", Line: i + 2, Err: err}
+		}
+
+		s.Used, err = strconv.Atoi(dat_s[3])
+		if err != nil {
+			return nil, &ParseError{File: procdir_swaps, Field: "used", Line: i + 2, Err: err}
+		}
+
+		s.Priority, err = strconv.Atoi(dat_s[4])
+		if err != nil {
+			return nil, &ParseError{File: procdir_swaps, Field: "priority", Line: i + 2, Err: err}
+		}
+
+		swaps = append(swaps, s)
+	}
+
+	if len(swaps) == 0 {
+		return nil, ErrNoSwap
+	}
+
+	return swaps, nil
+}
+
+// SwapTotals returns the total size and used size of swaps (KiB).
+func SwapTotals(swaps []Swap) (size int, used int) {
+	for _, s := range swaps {
+		size += s.Size
+		used += s.Used
+	}
+
+	return size, used
 }
 
 // GetSwapFilename returns the swap partition filename.
 func GetSwapFilename() (string, error) {
-	dat, err := os.ReadFile(procdir_swaps)
+	return defaultFS.GetSwapFilename()
+}
+
+// GetSwapFilename returns the swap partition filename.
+// Only the first swap device is considered, see GetSwaps.
+func (pfs FS) GetSwapFilename() (string, error) {
+	swaps, err := pfs.GetSwaps()
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_swaps)
 		return "", err
 	}
 
-	dat_s := strings.Split(string(dat), "\n")
-	if dat_s[1] == "" {
-		return "", fmt.Errorf("no swap partition")
-	}
-
-	s := strings.Split(strings.Join(strings.Fields(strings.TrimSpace(dat_s[1])), " "), " ")[0]
-
-	return s, err
+	return swaps[0].Filename, nil
 }
 
 // GetSwapType returns the swap partition type.
 func GetSwapType() (string, error) {
-	dat, err := os.ReadFile(procdir_swaps)
+	return defaultFS.GetSwapType()
+}
+
+// GetSwapType returns the swap partition type.
+// Only the first swap device is considered, see GetSwaps.
+func (pfs FS) GetSwapType() (string, error) {
+	swaps, err := pfs.GetSwaps()
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_swaps)
 		return "", err
 	}
 
-	dat_s := strings.Split(string(dat), "\n")
-	if dat_s[1] == "" {
-		return "", fmt.Errorf("no swap partition")
-	}
-
-	s := strings.Split(strings.Join(strings.Fields(strings.TrimSpace(dat_s[1])), " "), " ")[1]
-
-	return s, err
+	return swaps[0].Type, nil
 }
 
 // GetSwapSize returns the swap partition total size.
 func GetSwapSize() (int, error) {
-	dat, err := os.ReadFile(procdir_swaps)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_swaps)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), "\n")
-	if dat_s[1] == "" {
-		return 0, fmt.Errorf("no swap partition")
-	}
+	return defaultFS.GetSwapSize()
+}
 
-	s, err := strconv.Atoi(strings.Split(strings.Join(strings.Fields(strings.TrimSpace(dat_s[1])), " "), " ")[2])
+// GetSwapSize returns the swap partition total size.
+// Only the first swap device is considered, see GetSwaps.
+func (pfs FS) GetSwapSize() (int, error) {
+	swaps, err := pfs.GetSwaps()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return s, err
+	return swaps[0].Size, nil
 }
 
 // GetSwapUsed returns the swap partition used size.
 func GetSwapUsed() (int, error) {
-	dat, err := os.ReadFile(procdir_swaps)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_swaps)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), "\n")
-	if dat_s[1] == "" {
-		return 0, fmt.Errorf("no swap partition")
-	}
+	return defaultFS.GetSwapUsed()
+}
 
-	s, err := strconv.Atoi(strings.Split(strings.Join(strings.Fields(strings.TrimSpace(dat_s[1])), " "), " ")[3])
+// GetSwapUsed returns the swap partition used size.
+// Only the first swap device is considered, see GetSwaps.
+func (pfs FS) GetSwapUsed() (int, error) {
+	swaps, err := pfs.GetSwaps()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return s, err
+	return swaps[0].Used, nil
 }
 
 // GetSwapPriority returns the swap partition priority.
 func GetSwapPriority() (int, error) {
-	dat, err := os.ReadFile(procdir_swaps)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_swaps)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), "\n")
-	if dat_s[1] == "" {
-		return 0, fmt.Errorf("no swap partition")
-	}
+	return defaultFS.GetSwapPriority()
+}
 
-	s, err := strconv.Atoi(strings.Split(strings.Join(strings.Fields(strings.TrimSpace(dat_s[1])), " "), " ")[4])
+// GetSwapPriority returns the swap partition priority.
+// Only the first swap device is considered, see GetSwaps.
+func (pfs FS) GetSwapPriority() (int, error) {
+	swaps, err := pfs.GetSwaps()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return s, err
+	return swaps[0].Priority, nil
 }
 
 // GetUptimeSystem returns the uptime of the system (seconds).
 func GetUptimeSystem() (float64, error) {
-	dat, err := os.ReadFile(procdir_uptime)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_uptime)
-		return 0.0, err
-	}
-
-	dat_s := strings.Split(string(dat), " ")[0]
-
-	us, err := strconv.ParseFloat(dat_s, 32)
-	if err != nil {
-		fmt.Errorf("error parsing %v to float", dat_s)
-		return 0.0, err
-	}
+	return defaultFS.GetUptimeSystem()
+}
 
-	return us, nil
+// GetUptimeSystem returns the uptime of the system (seconds).
+func (pfs FS) GetUptimeSystem() (float64, error) {
+	return pfs.readUptime(0, "uptime")
 }
 
 // GetUptimeIdle returns the amount of time spent in idle process (seconds).
 func GetUptimeIdle() (float64, error) {
-	dat, err := os.ReadFile(procdir_uptime)
+	return defaultFS.GetUptimeIdle()
+}
+
+// GetUptimeIdle returns the amount of time spent in idle process (seconds).
+func (pfs FS) GetUptimeIdle() (float64, error) {
+	return pfs.readUptime(1, "idle")
+}
+
+// readUptime returns the i-th field of /proc/uptime, e.g. "350.50 690.25".
+func (pfs FS) readUptime(i int, field string) (float64, error) {
+	dat, err := pfs.readFile(procdir_uptime)
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_uptime)
 		return 0.0, err
 	}
 
-	dat_s := strings.Split(string(dat), " ")[1]
+	dat_s := strings.Fields(string(dat))
+	if len(dat_s) <= i {
+		return 0.0, &ParseError{File: procdir_uptime, Err: errUnexpectedFormat(string(dat))}
+	}
 
-	ui, err := strconv.ParseFloat(dat_s[:len(dat_s)-1], 32)
+	u, err := strconv.ParseFloat(dat_s[i], 64)
 	if err != nil {
-		fmt.Errorf("error parsing %v to float", dat_s)
-		return 0.0, err
+		return 0.0, &ParseError{File: procdir_uptime, Field: field, Err: err}
 	}
 
-	return ui, nil
+	return u, nil
 }
 
-// GetCpuUserTime returns the amount of time spent in user mode (USER_HZ).
-func GetCpuUserTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
+// GetStat returns the kernel/system statistics available in /proc/stat.
+func GetStat() (Stat, error) {
+	return defaultFS.GetStat()
+}
+
+// GetStat returns the kernel/system statistics available in /proc/stat.
+func (pfs FS) GetStat() (Stat, error) {
+	dat, err := pfs.readFile(procdir_stat)
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
-		return 0, err
+		return Stat{}, err
 	}
 
-	dat_s := strings.Split(string(dat), " ")[2]
+	var s Stat
 
-	s, err := strconv.Atoi(dat_s)
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+	for i, line := range strings.Split(string(dat), "\n") {
+		dat_s := strings.Fields(line)
+		if len(dat_s) < 2 {
+			continue
+		}
+
+		switch {
+		case dat_s[0] == "cpu":
+			s.CPU, err = parseCPUTimes(-1, dat_s[1:])
+		case strings.HasPrefix(dat_s[0], "cpu"):
+			var id int
+			id, err = strconv.Atoi(dat_s[0][len("cpu"):])
+			if err != nil {
+				break
+			}
+
+			var c CPUTimes
+			c, err = parseCPUTimes(id, dat_s[1:])
+			s.CPUs = append(s.CPUs, c)
+		case dat_s[0] == "intr":
+			s.Intr, s.IntrCounts, err = parseCounters(dat_s[1:])
+		case dat_s[0] == "ctxt":
+			s.Ctxt, err = strconv.ParseUint(dat_s[1], 10, 64)
+		case dat_s[0] == "btime":
+			s.Btime, err = strconv.ParseUint(dat_s[1], 10, 64)
+		case dat_s[0] == "processes":
+			s.Processes, err = strconv.ParseUint(dat_s[1], 10, 64)
+		case dat_s[0] == "procs_running":
+			s.ProcsRunning, err = strconv.ParseUint(dat_s[1], 10, 64)
+		case dat_s[0] == "procs_blocked":
+			s.ProcsBlocked, err = strconv.ParseUint(dat_s[1], 10, 64)
+		case dat_s[0] == "softirq":
+			s.Softirq, s.SoftirqCounts, err = parseCounters(dat_s[1:])
+		}
+
+		if err != nil {
+			return Stat{}, &ParseError{File: procdir_stat, Field: dat_s[0], Line: i + 1, Err: err}
+		}
 	}
 
 	return s, nil
 }
 
-// GetCpuNiceTime returns the amount of time spent in user mode with low priority (USER_HZ).
-func GetCpuNiceTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
-		return 0, err
+// parseCPUTimes parses the columns of a "cpu" line of /proc/stat.
+// Columns missing on older kernels are left zero.
+func parseCPUTimes(id int, dat_s []string) (CPUTimes, error) {
+	c := CPUTimes{ID: id}
+
+	fields := []*uint64{
+		&c.User, &c.Nice, &c.System, &c.Idle, &c.Iowait,
+		&c.Irq, &c.Softirq, &c.Steal, &c.Guest, &c.GuestNice,
+	}
+
+	for i := 0; i < len(fields) && i < len(dat_s); i++ {
+		v, err := strconv.ParseUint(dat_s[i], 10, 64)
+		if err != nil {
+			return CPUTimes{}, err
+		}
+		*fields[i] = v
 	}
 
-	dat_s := strings.Split(string(dat), " ")[3]
+	return c, nil
+}
 
-	s, err := strconv.Atoi(dat_s)
+// parseCounters parses a "<total> <count>..." line of /proc/stat, e.g. intr or softirq.
+func parseCounters(dat_s []string) (uint64, []uint64, error) {
+	total, err := strconv.ParseUint(dat_s[0], 10, 64)
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+		return 0, nil, err
 	}
 
-	return s, nil
+	counts := make([]uint64, 0, len(dat_s)-1)
+	for _, f := range dat_s[1:] {
+		v, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return 0, nil, err
+		}
+		counts = append(counts, v)
+	}
+
+	return total, counts, nil
 }
 
-// GetCpuSystemTime returns the amount of time spent in system mode (USER_HZ).
-func GetCpuSystemTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
+// GetCpuUserTime returns the amount of time spent in user mode (USER_HZ).
+func GetCpuUserTime() (int, error) {
+	return defaultFS.GetCpuUserTime()
+}
+
+// GetCpuUserTime returns the amount of time spent in user mode (USER_HZ).
+func (pfs FS) GetCpuUserTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
 		return 0, err
 	}
 
-	dat_s := strings.Split(string(dat), " ")[4]
+	return int(s.CPU.User), nil
+}
 
-	s, err := strconv.Atoi(dat_s)
+// GetCpuNiceTime returns the amount of time spent in user mode with low priority (USER_HZ).
+func GetCpuNiceTime() (int, error) {
+	return defaultFS.GetCpuNiceTime()
+}
+
+// GetCpuNiceTime returns the amount of time spent in user mode with low priority (USER_HZ).
+func (pfs FS) GetCpuNiceTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+		return 0, err
 	}
 
-	return s, nil
+	return int(s.CPU.Nice), nil
 }
 
-// GetCpuIdleTime returns the amount of time spent in the idle task (USER_HZ times UptimeIdle).
-func GetCpuIdleTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
+// GetCpuSystemTime returns the amount of time spent in system mode (USER_HZ).
+func GetCpuSystemTime() (int, error) {
+	return defaultFS.GetCpuSystemTime()
+}
+
+// GetCpuSystemTime returns the amount of time spent in system mode (USER_HZ).
+func (pfs FS) GetCpuSystemTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
 		return 0, err
 	}
 
-	dat_s := strings.Split(string(dat), " ")[5]
+	return int(s.CPU.System), nil
+}
+
+// GetCpuIdleTime returns the amount of time spent in the idle task (USER_HZ times UptimeIdle).
+func GetCpuIdleTime() (int, error) {
+	return defaultFS.GetCpuIdleTime()
+}
 
-	s, err := strconv.Atoi(dat_s)
+// GetCpuIdleTime returns the amount of time spent in the idle task (USER_HZ times UptimeIdle).
+func (pfs FS) GetCpuIdleTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+		return 0, err
 	}
 
-	return s, nil
+	return int(s.CPU.Idle), nil
 }
 
 // GetCpuIowaitTime returns the amount of time waiting for I/O to complete (USER_HZ).
 func GetCpuIowaitTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), " ")[6]
+	return defaultFS.GetCpuIowaitTime()
+}
 
-	s, err := strconv.Atoi(dat_s)
+// GetCpuIowaitTime returns the amount of time waiting for I/O to complete (USER_HZ).
+func (pfs FS) GetCpuIowaitTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+		return 0, err
 	}
 
-	return s, nil
+	return int(s.CPU.Iowait), nil
 }
 
 // GetCpuIrqTime returns the amount of time servicing interrupts (USER_HZ).
 func GetCpuIrqTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), " ")[7]
+	return defaultFS.GetCpuIrqTime()
+}
 
-	s, err := strconv.Atoi(dat_s)
+// GetCpuIrqTime returns the amount of time servicing interrupts (USER_HZ).
+func (pfs FS) GetCpuIrqTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+		return 0, err
 	}
 
-	return s, nil
+	return int(s.CPU.Irq), nil
 }
 
 // GetCpuSoftirqTime returns the amount of time servicing softirqs(USER_HZ).
 func GetCpuSoftirqTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), " ")[8]
+	return defaultFS.GetCpuSoftirqTime()
+}
 
-	s, err := strconv.Atoi(dat_s)
+// GetCpuSoftirqTime returns the amount of time servicing softirqs(USER_HZ).
+func (pfs FS) GetCpuSoftirqTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+		return 0, err
 	}
 
-	return s, nil
+	return int(s.CPU.Softirq), nil
 }
 
 // GetCpuStealTime returns the amount of time spent in other operating systems when running in a virtualized environment (USER_HZ).
 func GetCpuStealTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), " ")[9]
+	return defaultFS.GetCpuStealTime()
+}
 
-	s, err := strconv.Atoi(dat_s)
+// GetCpuStealTime returns the amount of time spent in other operating systems when running in a virtualized environment (USER_HZ).
+func (pfs FS) GetCpuStealTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+		return 0, err
 	}
 
-	return s, nil
+	return int(s.CPU.Steal), nil
 }
 
 // GetCpuGuestTime returns the amount of time spent running a virtual CPU for guest operating systems (USER_HZ).
 func GetCpuGuestTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), " ")[10]
+	return defaultFS.GetCpuGuestTime()
+}
 
-	s, err := strconv.Atoi(dat_s)
+// GetCpuGuestTime returns the amount of time spent running a virtual CPU for guest operating systems (USER_HZ).
+func (pfs FS) GetCpuGuestTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+		return 0, err
 	}
 
-	return s, nil
+	return int(s.CPU.Guest), nil
 }
 
 // GetCpuGuestNiceTime returns the amount of time spent running a niced virtual CPU for guest operating systems (USER_HZ).
 func GetCpuGuestNiceTime() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), " ")[11]
+	return defaultFS.GetCpuGuestNiceTime()
+}
 
-	s, err := strconv.Atoi(dat_s)
+// GetCpuGuestNiceTime returns the amount of time spent running a niced virtual CPU for guest operating systems (USER_HZ).
+func (pfs FS) GetCpuGuestNiceTime() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
+		return 0, err
 	}
 
-	return s, nil
+	return int(s.CPU.GuestNice), nil
 }
 
 // GetProcessesBlockedSize returns the number of blocked processes in the system.
-// FIXME
 func GetProcessesBlockedSize() (int, error) {
-	dat, err := os.ReadFile(procdir_stat)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_stat)
-		return 0, err
-	}
-
-	dat_s := strings.Split(string(dat), " ")[14]
-
-	fmt.Println(dat_s)
+	return defaultFS.GetProcessesBlockedSize()
+}
 
-	blksz, err := strconv.Atoi(strings.Split(dat_s, " ")[1])
+// GetProcessesBlockedSize returns the number of blocked processes in the system.
+func (pfs FS) GetProcessesBlockedSize() (int, error) {
+	s, err := pfs.GetStat()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return blksz, nil
+	return int(s.ProcsBlocked), nil
 }
 
 // GetPerProcessStat returns a slice of Procstat containing per-process (all living processes in the system) stat information.
 func GetPerProcessStat() ([]Procstat, error) {
+	return defaultFS.GetPerProcessStat()
+}
 
-	var pps_s []Procstat
-
-	files, err := ioutil.ReadDir(procdir)
-	if err != nil {
-		fmt.Printf("Error reading %s\n", procdir)
-	}
-
-	// Walking though /proc
-	for _, f := range files {
-		// FIXME: find a clever way of doing it.
-		if f.Name() == "acpi" { // acpi is the first directory after the last PID.
-			break
-		}
-
-		pid, err := strconv.Atoi(f.Name())
-		if err != nil {
-			fmt.Errorf("Error parsing %v to int\n", f.Name())
-			return []Procstat{}, err
-		}
-
-		// get stats of <pid> via GetProcessStat()
-		p, err := GetProcessStat(pid)
-		if err != nil {
-			fmt.Errorf("Error getting stats from PID %v\n", pid)
-			return []Procstat{}, err
-		}
-
-		// Append p element into pps_s slice.
-		pps_s = append(pps_s, p)
-	}
+// GetPerProcessStat returns a slice of Procstat containing per-process (all living processes in the system) stat information.
+// Processes that exit during the scan are skipped. If some other processes
+// cannot be read, the remaining ones are returned together with a *ScanError.
+func (pfs FS) GetPerProcessStat() ([]Procstat, error) {
+	return pfs.GetPerProcessStatWithOptions(StatScanOptions{})
+}
 
-	return pps_s, nil
+// GetPerProcessStatWithOptions is like GetPerProcessStat, with the scan configured by opts.
+func GetPerProcessStatWithOptions(opts StatScanOptions) ([]Procstat, error) {
+	return defaultFS.GetPerProcessStatWithOptions(opts)
 }
 
-// GetProcessStat returns stat information of a giving process.
-func GetProcessStat(pid int) (Procstat, error) {
+// GetPerProcessStatWithOptions is like GetPerProcessStat, with the scan configured by opts.
+// The result is in ascending PID order regardless of opts.Workers.
+func (pfs FS) GetPerProcessStatWithOptions(opts StatScanOptions) ([]Procstat, error) {
+	return pfs.GetPerProcessStatContext(context.Background(), opts)
+}
 
-	statFile := procdir + "/" + strconv.Itoa(pid) + "/" + procdir_per_process_stat
+// GetPerProcessStatContext is like GetPerProcessStatWithOptions, but stops when ctx is done.
+func GetPerProcessStatContext(ctx context.Context, opts StatScanOptions) ([]Procstat, error) {
+	return defaultFS.GetPerProcessStatContext(ctx, opts)
+}
 
-	dat, err := os.ReadFile(statFile)
-	if err != nil {
-		fmt.Errorf("Error reading %s\n", statFile)
-		return Procstat{}, err
+// GetPerProcessStatContext is like GetPerProcessStatWithOptions, but stops when ctx is done.
+// In that case, the processes read so far are returned together with ctx.Err().
+func (pfs FS) GetPerProcessStatContext(ctx context.Context, opts StatScanOptions) ([]Procstat, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	dat_s := strings.Split(string(dat), " ")
-
-	var p Procstat
-
-	// Parsing $statFile into Procstat fields.
-	p.Pid, err = strconv.Atoi(dat_s[0])
+	pids, err := pfs.pids()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+		return nil, err
 	}
 
-	// Verify quantity of Comm words length in dat_s
-	i := 52 - len(dat_s)
+	pps_s := make([]Procstat, len(pids))
+	found := make([]bool, len(pids))
 
-	if i != 0 {
-		i *= -1
-
-		var Comm strings.Builder
-
-		for j := 1; j < i+2; j++ {
-			Comm.WriteString(dat_s[j])
+	read := func(i int) (interface{}, error) {
+		p, err := pfs.GetProcessStat(pids[i])
+		if err != nil {
+			return p, err
 		}
-		p.Comm = Comm.String()[1 : len(Comm.String())-1]
-	} else {
-		p.Comm = string(dat_s[1])[1 : len(dat_s[1])-1]
-	}
-
-	p.State = dat_s[i+2]
-
-	p.Ppid, err = strconv.Atoi(dat_s[i+3])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
-
-	p.Pgrp, err = strconv.Atoi(dat_s[i+4])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
-
-	p.Session, err = strconv.Atoi(dat_s[i+5])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
-
-	p.TtyNr, err = strconv.Atoi(dat_s[i+6])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
-
-	p.Tpgid, err = strconv.Atoi(dat_s[i+7])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
-
-	p.Flags, err = strconv.Atoi(dat_s[i+8])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
-
-	p.Minflt, err = strconv.Atoi(dat_s[i+9])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
 
-	p.Cminflt, err = strconv.Atoi(dat_s[i+10])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
-
-	p.Majflt, err = strconv.Atoi(dat_s[i+11])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
-
-	p.Cmajflt, err = strconv.Atoi(dat_s[i+12])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+		if opts.Cmdline {
+			if p.Cmdline, err = pfs.GetProcessCmdline(pids[i]); err != nil {
+				return p, err
+			}
+		}
 
-	p.Utime, err = strconv.Atoi(dat_s[i+13])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+		if opts.Threads {
+			threads, err := pfs.GetProcessThreadsContext(ctx, pids[i])
+			if err != nil {
+				return p, err
+			}
 
-	p.Stime, err = strconv.Atoi(dat_s[i+14])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+			p.Threads = make([]Procstat, len(threads))
+			for j, t := range threads {
+				p.Threads[j] = t.Procstat
+			}
+		}
 
-	p.Cutime, err = strconv.Atoi(dat_s[i+15])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+		return p, nil
 	}
 
-	p.Cstime, err = strconv.Atoi(dat_s[i+16])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+	collect := func(i int, v interface{}) {
+		pps_s[i] = v.(Procstat)
+		found[i] = true
 	}
 
-	p.Priority, err = strconv.Atoi(dat_s[i+17])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	errs, err := scan(ctx, len(pids), opts.Workers, read, collect)
 
-	p.Nice, err = strconv.Atoi(dat_s[i+18])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
-
-	p.NumThreads, err = strconv.Atoi(dat_s[i+19])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+	pps := make([]Procstat, 0, len(pids))
+	for _, i := range foundIndices(found) {
+		pps = append(pps, pps_s[i])
 	}
 
-	p.Itrealvalue, err = strconv.Atoi(dat_s[i+20])
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+		return pps, err
 	}
 
-	p.Starttime, err = strconv.Atoi(dat_s[i+21])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	return pps, newScanError(pids, errs)
+}
 
-	p.Vsize, err = strconv.Atoi(dat_s[i+22])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+// GetProcessStat returns stat information of a giving process.
+func GetProcessStat(pid int) (Procstat, error) {
+	return defaultFS.GetProcessStat(pid)
+}
 
-	p.Rss, err = strconv.Atoi(dat_s[i+23])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+// GetProcessStat returns stat information of a giving process.
+func (pfs FS) GetProcessStat(pid int) (Procstat, error) {
 
-	p.Rsslim = dat_s[24]
+	statFile := path.Join(strconv.Itoa(pid), procdir_per_process_stat)
 
-	p.Startcode, err = strconv.Atoi(dat_s[i+25])
+	dat, err := pfs.readFile(statFile)
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return Procstat{}, err
 	}
 
-	p.Endcode, err = strconv.Atoi(dat_s[i+26])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	return parseProcstat(statFile, dat)
+}
 
-	p.Startstack, err = strconv.Atoi(dat_s[i+27])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+// procstatFields are the names, as in proc(5), of the /proc/<pid>/stat fields following state.
+var procstatFields = []string{
+	"ppid", "pgrp", "session", "tty_nr", "tpgid", "flags", "minflt",
+	"cminflt", "majflt", "cmajflt", "utime", "stime", "cutime",
+	"cstime", "priority", "nice", "num_threads", "itrealvalue",
+	"starttime", "vsize", "rss", "rsslim", "startcode", "endcode",
+	"startstack", "kstkesp", "kstkeip", "signal", "blocked", "sigignore",
+	"sigcatch", "wchan", "nswap", "cnswap", "exit_signal", "processor",
+	"rt_priority", "policy", "delayacct_blkio_ticks", "guest_time",
+	"cguest_time", "start_data", "end_data", "start_brk", "arg_start",
+	"arg_end", "env_start", "env_end", "exit_code",
+}
 
-	p.Kstkesp, err = strconv.Atoi(dat_s[i+28])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+// parseProcstat parses the content of a /proc/<pid>/stat file named file.
+func parseProcstat(file string, dat []byte) (Procstat, error) {
+	s := strings.TrimSpace(string(dat))
 
-	p.Kstkeip, err = strconv.Atoi(dat_s[i+29])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+	// Comm may contain spaces and parentheses, e.g. "1 (tmux: server) S ...",
+	// so it ends at the last ')'.
+	i := strings.IndexByte(s, '(')
+	j := strings.LastIndexByte(s, ')')
+	if i < 0 || j < i {
+		return Procstat{}, &ParseError{File: file, Err: errUnexpectedFormat(s)}
 	}
 
-	p.Signal, err = strconv.Atoi(dat_s[i+30])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	var p Procstat
+	var err error
 
-	p.Blocked, err = strconv.Atoi(dat_s[i+31])
+	p.Pid, err = strconv.Atoi(strings.TrimSpace(s[:i]))
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+		return Procstat{}, &ParseError{File: file, Field: "pid", Err: err}
 	}
 
-	p.Sigignore, err = strconv.Atoi(dat_s[i+32])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	p.Comm = s[i+1 : j]
 
-	p.Sigcatch, err = strconv.Atoi(dat_s[i+33])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+	dat_s := strings.Fields(s[j+1:])
+	if len(dat_s) == 0 {
+		return Procstat{}, &ParseError{File: file, Err: errUnexpectedFormat(s)}
 	}
 
-	p.Wchan, err = strconv.Atoi(dat_s[i+34])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	p.State = dat_s[0]
+	p.NumFields = 2 + len(dat_s)
 
-	p.Nswap, err = strconv.Atoi(dat_s[i+35])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+	// Fields following State, in the order emitted by the kernel.
+	fields := []interface{}{
+		&p.Ppid, &p.Pgrp, &p.Session, &p.TtyNr, &p.Tpgid, &p.Flags, &p.Minflt,
+		&p.Cminflt, &p.Majflt, &p.Cmajflt, &p.Utime, &p.Stime, &p.Cutime,
+		&p.Cstime, &p.Priority, &p.Nice, &p.NumThreads, &p.Itrealvalue,
+		&p.Starttime, &p.Vsize, &p.Rss, &p.Rsslim, &p.Startcode, &p.Endcode,
+		&p.Startstack, &p.Kstkesp, &p.Kstkeip, &p.Signal, &p.Blocked, &p.Sigignore,
+		&p.Sigcatch, &p.Wchan, &p.Nswap, &p.Cnswap, &p.ExitSignal, &p.Processor,
+		&p.RtPriority, &p.Policy, &p.DelayacctBlkioTicks, &p.GuestTime,
+		&p.CguestTime, &p.StartData, &p.EndData, &p.StartBrk, &p.ArgStart,
+		&p.ArgEnd, &p.EnvStart, &p.EnvEnd, &p.ExitCode,
 	}
 
-	p.Cnswap, err = strconv.Atoi(dat_s[i+36])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	for k, f := range fields {
+		if k+1 >= len(dat_s) {
+			break
+		}
 
-	p.ExitSignal, err = strconv.Atoi(dat_s[i+37])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+		v := dat_s[k+1]
 
-	p.Processor, err = strconv.Atoi(dat_s[i+38])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+		switch f := f.(type) {
+		case *int:
+			*f, err = strconv.Atoi(v)
+		case *int64:
+			*f, err = strconv.ParseInt(v, 10, 64)
+		case *uint64:
+			*f, err = strconv.ParseUint(v, 10, 64)
+		}
 
-	p.RtPriority, err = strconv.Atoi(dat_s[i+39])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+		if err != nil {
+			return Procstat{}, &ParseError{File: file, Field: procstatFields[k], Err: err}
+		}
 	}
 
-	p.Policy, err = strconv.Atoi(dat_s[i+40])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	return p, nil
+}
 
-	p.DelayacctBlkioTicks, err = strconv.Atoi(dat_s[i+41])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+// GetMeminfo returns the memory usage information available in /proc/meminfo.
+func GetMeminfo() (Meminfo, error) {
+	return defaultFS.GetMeminfo()
+}
 
-	p.GuestTime, err = strconv.Atoi(dat_s[i+42])
+// GetMeminfo returns the memory usage information available in /proc/meminfo.
+func (pfs FS) GetMeminfo() (Meminfo, error) {
+	dat, err := pfs.readFile(procdir_meminfo)
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+		return Meminfo{}, err
 	}
 
-	p.CguestTime, err = strconv.Atoi(dat_s[i+43])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	var m Meminfo
+	fields := m.fields()
 
-	p.StartData, err = strconv.Atoi(dat_s[i+44])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	for n, line := range strings.Split(string(dat), "\n") {
+		// e.g. "MemTotal:        6158152 kB" or "HugePages_Total:       0"
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
 
-	p.EndData, err = strconv.Atoi(dat_s[i+45])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+		key := line[:i]
+		dat_s := strings.Fields(line[i+1:])
+		if len(dat_s) == 0 {
+			return Meminfo{}, &ParseError{File: procdir_meminfo, Field: key, Line: n + 1, Err: errUnexpectedFormat(line)}
+		}
 
-	p.StartBrk, err = strconv.Atoi(dat_s[i+46])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+		v, err := strconv.ParseUint(dat_s[0], 10, 64)
+		if err != nil {
+			return Meminfo{}, &ParseError{File: procdir_meminfo, Field: key, Line: n + 1, Err: err}
+		}
 
-	p.ArgStart, err = strconv.Atoi(dat_s[i+47])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+		if len(dat_s) > 1 && dat_s[1] == "kB" {
+			v *= 1024
+		}
 
-	p.ArgEnd, err = strconv.Atoi(dat_s[i+48])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+		if f, ok := fields[key]; ok {
+			*f = v
+			continue
+		}
 
-	p.EnvStart, err = strconv.Atoi(dat_s[i+49])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+		if m.Other == nil {
+			m.Other = make(map[string]uint64)
+		}
+		m.Other[key] = v
 	}
 
-	p.EnvEnd, err = strconv.Atoi(dat_s[i+50])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
-	}
+	return m, nil
+}
 
-	p.ExitCode, err = strconv.Atoi(string(dat_s[i+51])[:len(dat_s[i+51])-1])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return Procstat{}, err
+// fields maps the /proc/meminfo keys to the Meminfo fields.
+func (m *Meminfo) fields() map[string]*uint64 {
+	return map[string]*uint64{
+		"MemTotal":          &m.MemTotal,
+		"MemFree":           &m.MemFree,
+		"MemAvailable":      &m.MemAvailable,
+		"Buffers":           &m.Buffers,
+		"Cached":            &m.Cached,
+		"SwapCached":        &m.SwapCached,
+		"Active":            &m.Active,
+		"Inactive":          &m.Inactive,
+		"Active(anon)":      &m.ActiveAnon,
+		"Inactive(anon)":    &m.InactiveAnon,
+		"Active(file)":      &m.ActiveFile,
+		"Inactive(file)":    &m.InactiveFile,
+		"Unevictable":       &m.Unevictable,
+		"Mlocked":           &m.Mlocked,
+		"SwapTotal":         &m.SwapTotal,
+		"SwapFree":          &m.SwapFree,
+		"Zswap":             &m.Zswap,
+		"Zswapped":          &m.Zswapped,
+		"Dirty":             &m.Dirty,
+		"Writeback":         &m.Writeback,
+		"AnonPages":         &m.AnonPages,
+		"Mapped":            &m.Mapped,
+		"Shmem":             &m.Shmem,
+		"KReclaimable":      &m.KReclaimable,
+		"Slab":              &m.Slab,
+		"SReclaimable":      &m.SReclaimable,
+		"SUnreclaim":        &m.SUnreclaim,
+		"KernelStack":       &m.KernelStack,
+		"PageTables":        &m.PageTables,
+		"SecPageTables":     &m.SecPageTables,
+		"NFS_Unstable":      &m.NFSUnstable,
+		"Bounce":            &m.Bounce,
+		"WritebackTmp":      &m.WritebackTmp,
+		"CommitLimit":       &m.CommitLimit,
+		"Committed_AS":      &m.CommittedAS,
+		"VmallocTotal":      &m.VmallocTotal,
+		"VmallocUsed":       &m.VmallocUsed,
+		"VmallocChunk":      &m.VmallocChunk,
+		"Percpu":            &m.Percpu,
+		"HardwareCorrupted": &m.HardwareCorrupted,
+		"AnonHugePages":     &m.AnonHugePages,
+		"ShmemHugePages":    &m.ShmemHugePages,
+		"ShmemPmdMapped":    &m.ShmemPmdMapped,
+		"FileHugePages":     &m.FileHugePages,
+		"FilePmdMapped":     &m.FilePmdMapped,
+		"HugePages_Total":   &m.HugePagesTotal,
+		"HugePages_Free":    &m.HugePagesFree,
+		"HugePages_Rsvd":    &m.HugePagesRsvd,
+		"HugePages_Surp":    &m.HugePagesSurp,
+		"Hugepagesize":      &m.Hugepagesize,
+		"Hugetlb":           &m.Hugetlb,
+		"DirectMap4k":       &m.DirectMap4k,
+		"DirectMap2M":       &m.DirectMap2M,
+		"DirectMap1G":       &m.DirectMap1G,
 	}
-
-	return p, nil
 }
 
-// GetMemTotal returns the total memory
+// GetMemTotal returns the total memory (kB)
 func GetMemTotal() (int, error) {
-	dat, err := os.ReadFile(procdir_meminfo)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_meminfo)
-	}
-
-	dat_s := strings.Split(string(dat), "\n")
+	return defaultFS.GetMemTotal()
+}
 
-	s, err := strconv.Atoi(strings.Fields(dat_s[0])[1])
+// GetMemTotal returns the total memory (kB)
+func (pfs FS) GetMemTotal() (int, error) {
+	m, err := pfs.GetMeminfo()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return s, err
+	return int(m.MemTotal / 1024), nil
 }
 
-// GetMemFree returns the free memory
+// GetMemFree returns the free memory (kB)
 func GetMemFree() (int, error) {
-	dat, err := os.ReadFile(procdir_meminfo)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_meminfo)
-	}
-
-	dat_s := strings.Split(string(dat), "\n")
+	return defaultFS.GetMemFree()
+}
 
-	s, err := strconv.Atoi(strings.Fields(dat_s[1])[1])
+// GetMemFree returns the free memory (kB)
+func (pfs FS) GetMemFree() (int, error) {
+	m, err := pfs.GetMeminfo()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return s, err
+	return int(m.MemFree / 1024), nil
 }
 
-// GetMemUsed returns the memory used
+// GetMemUsed returns the memory used (kB)
 func GetMemUsed() (int, error) {
-	dat, err := os.ReadFile(procdir_meminfo)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_meminfo)
-	}
-
-	dat_s := strings.Split(string(dat), "\n")
-
-	t, err := strconv.Atoi(strings.Fields(dat_s[0])[1])
-	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
-		return 0, err
-	}
+	return defaultFS.GetMemUsed()
+}
 
-	f, err := strconv.Atoi(strings.Fields(dat_s[1])[1])
+// GetMemUsed returns the memory used (kB)
+func (pfs FS) GetMemUsed() (int, error) {
+	m, err := pfs.GetMeminfo()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return t - f, err
+	return int((m.MemTotal - m.MemFree) / 1024), nil
 }
 
-// GetMemAvailable returns available memory
+// GetMemAvailable returns available memory (kB)
 func GetMemAvailable() (int, error) {
-	dat, err := os.ReadFile(procdir_meminfo)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_meminfo)
-	}
-
-	dat_s := strings.Split(string(dat), "\n")
+	return defaultFS.GetMemAvailable()
+}
 
-	s, err := strconv.Atoi(strings.Fields(dat_s[2])[1])
+// GetMemAvailable returns available memory (kB)
+func (pfs FS) GetMemAvailable() (int, error) {
+	m, err := pfs.GetMeminfo()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return s, err
+	return int(m.MemAvailable / 1024), nil
 }
 
-// GetMemBuffers returns the memory buffers
+// GetMemBuffers returns the memory buffers (kB)
 func GetMemBuffers() (int, error) {
-	dat, err := os.ReadFile(procdir_meminfo)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_meminfo)
-	}
-
-	dat_s := strings.Split(string(dat), "\n")
+	return defaultFS.GetMemBuffers()
+}
 
-	s, err := strconv.Atoi(strings.Fields(dat_s[3])[1])
+// GetMemBuffers returns the memory buffers (kB)
+func (pfs FS) GetMemBuffers() (int, error) {
+	m, err := pfs.GetMeminfo()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return s, err
+	return int(m.Buffers / 1024), nil
 }
 
-// GetMemCached returns the memory cached
+// GetMemCached returns the memory cached (kB)
 func GetMemCached() (int, error) {
-	dat, err := os.ReadFile(procdir_meminfo)
-	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_meminfo)
-	}
-
-	dat_s := strings.Split(string(dat), "\n")
+	return defaultFS.GetMemCached()
+}
 
-	s, err := strconv.Atoi(strings.Fields(dat_s[4])[1])
+// GetMemCached returns the memory cached (kB)
+func (pfs FS) GetMemCached() (int, error) {
+	m, err := pfs.GetMeminfo()
 	if err != nil {
-		fmt.Errorf("error parsing %v", dat_s)
 		return 0, err
 	}
 
-	return s, err
+	return int(m.Cached / 1024), nil
 }
 
 // GetKernelRelease returns the kernel version with additional information.
 func GetKernelRelease() (string, error) {
-	dat, err := os.ReadFile(procdir_osrelease)
+	return defaultFS.GetKernelRelease()
+}
+
+// GetKernelRelease returns the kernel version with additional information.
+func (pfs FS) GetKernelRelease() (string, error) {
+	dat, err := pfs.readFile(procdir_osrelease)
 	if err != nil {
-		fmt.Errorf("unable to read the file %v", procdir_osrelease)
 		return "", err
 	}
 
-	return string(dat), err
+	return string(dat), nil
 }
@@ -1,227 +1,407 @@
 package lpfs
 
 import (
-	"fmt"
+	"errors"
+	"os"
+	"reflect"
 	"testing"
+	"testing/fstest"
 )
 
-//	TestLoadAverage tests all functions that get data from /proc/loadavg.
+// testProcFS is a procfs fixture used to assert exact values.
+var testProcFS = fstest.MapFS{
+	"loadavg": {Data: []byte("0.50 0.25 1.75 2/613 12345\n")},
+	"swaps": {Data: []byte("Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n" +
+		"/dev/dm-1                               partition\t8388604\t\t1024\t\t-2\n" +
+		"/dev/zram0                              partition\t4194300\t\t512\t\t100\n" +
+		"/swapfile                               file\t\t1048572\t\t0\t\t-3\n")},
+	"uptime": {Data: []byte("350.50 690.25\n")},
+	"stat": {Data: []byte("cpu  3672 12 618 48101 822 3 7 22 5 1\n" +
+		"cpu0 2000 10 300 24000 400 2 4 11 3 1\n" +
+		"cpu1 1672 2 318 24101 422 1 3 11 2 0\n" +
+		"intr 72198 0 0 0\n" +
+		"ctxt 164395\n" +
+		"btime 1700000000\n" +
+		"processes 3620\n" +
+		"procs_running 1\n" +
+		"procs_blocked 2\n" +
+		"softirq 41066 0 2851 0 2024 0 0 1 8533 0 27657\n")},
+	"meminfo": {Data: []byte("MemTotal:        6158152 kB\n" +
+		"MemFree:         5160632 kB\n" +
+		"MemAvailable:    5693716 kB\n" +
+		"Buffers:           58072 kB\n" +
+		"Cached:           678316 kB\n" +
+		"SwapCached:            0 kB\n" +
+		"Active:           551052 kB\n" +
+		"Active(anon):     334680 kB\n" +
+		"SwapTotal:      13631476 kB\n" +
+		"Dirty:               128 kB\n" +
+		"Shmem:              4784 kB\n" +
+		"Slab:              71932 kB\n" +
+		"SReclaimable:      44404 kB\n" +
+		"Committed_AS:    1462780 kB\n" +
+		"HugePages_Total:       4\n" +
+		"Hugepagesize:       2048 kB\n" +
+		"FutureField:          16 kB\n" +
+		"FutureCount:           3\n")},
+	"sys/kernel/osrelease": {Data: []byte("6.1.0-13-amd64\n")},
+	"1/stat":               {Data: []byte("1 (systemd) S 0 1 1 0 -1 4194560 46427 3183421 114 1180 122 285 11463 2669 20 0 1 0 27 172404736 3199 18446744073709551615 1 1 0 0 0 0 671173123 4096 1260 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"42/stat":              {Data: []byte("42 (kworker/0:1-events) I 2 0 0 0 -1 69238880 0 0 0 0 0 5 0 0 20 0 1 0 5 0 0 18446744073709551615 0 0 0 0 0 0 0 2147483647 0 1 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"acpi/wakeup":          {Data: []byte("Device\tS-state\t  Status   Sysfs node\n")},
+}
+
+// TestLoadAverage tests all functions that get data from /proc/loadavg.
 func TestLoadAverage(t *testing.T) {
-	l1, err := GetLoadAverage1()
-	if err != nil {
-		t.Errorf("%v", err)
+	fs := NewFSFromFS(testProcFS)
+
+	l, err := fs.GetLoadavg()
+	want := Loadavg{Load1: 0.5, Load5: 0.25, Load15: 1.75, Runnable: 2, Tasks: 613, LastPid: 12345}
+	if err != nil || l != want {
+		t.Errorf("GetLoadavg() = %+v, %v; want %+v", l, err, want)
 	}
-	fmt.Printf("LoadAverage1(): %v, err: %v\n", l1, err)
 
-	l5, err := GetLoadAverage5()
-	if err != nil {
-		t.Errorf("%v", err)
+	bad := NewFSFromFS(fstest.MapFS{"loadavg": {Data: []byte("0.50 0.25\n")}})
+	if _, err := bad.GetLoadavg(); err == nil {
+		t.Errorf("GetLoadavg() of a truncated file returned no error")
 	}
-	fmt.Printf("LoadAverage5(): %v, err: %v\n", l5, err)
 
-	l15, err := GetLoadAverage15()
-	if err != nil {
-		t.Errorf("%v", err)
+	l1, err := fs.GetLoadAverage1()
+	if err != nil || l1 != 0.5 {
+		t.Errorf("GetLoadAverage1() = %v, %v; want 0.5", l1, err)
 	}
-	fmt.Printf("LoadAverage15(): %v, err: %v\n", l15, err)
 
-	tskq, err := GetTaskQueueSize()
-	if err != nil {
-		t.Errorf("%v", err)
+	l5, err := fs.GetLoadAverage5()
+	if err != nil || l5 != 0.25 {
+		t.Errorf("GetLoadAverage5() = %v, %v; want 0.25", l5, err)
 	}
 
-	fmt.Printf("GetTaskQueueSize(): %v, err: %v\n", tskq, err)
-	rszq, err := GetRunnableQueueSize()
-	if err != nil {
-		t.Errorf("%v", err)
+	l15, err := fs.GetLoadAverage15()
+	if err != nil || l15 != 1.75 {
+		t.Errorf("GetLoadAverage15() = %v, %v; want 1.75", l15, err)
 	}
-	fmt.Printf("GetRunnableQueueSize(): %v, err: %v\n", rszq, err)
 
-	pid, err := GetMostRecentPid()
-	if err != nil {
-		t.Errorf("%v", err)
+	tskq, err := fs.GetTaskQueueSize()
+	if err != nil || tskq != 613 {
+		t.Errorf("GetTaskQueueSize() = %v, %v; want 613", tskq, err)
+	}
+
+	rszq, err := fs.GetRunnableQueueSize()
+	if err != nil || rszq != 2 {
+		t.Errorf("GetRunnableQueueSize() = %v, %v; want 2", rszq, err)
+	}
+
+	pid, err := fs.GetMostRecentPid()
+	if err != nil || pid != 12345 {
+		t.Errorf("GetMostRecentPid() = %v, %v; want 12345", pid, err)
 	}
-	fmt.Printf("GetMostRecentPid(): %v, err: %v\n", pid, err)
 }
 
-//	TestSwaps tests all functions that get data from /proc/swaps.
+// TestSwaps tests all functions that get data from /proc/swaps.
 func TestSwaps(t *testing.T) {
-	sf, err := GetSwapFilename()
-	if err != nil {
-		t.Errorf("%v", err)
+	fs := NewFSFromFS(testProcFS)
+
+	swaps, err := fs.GetSwaps()
+	want := []Swap{
+		{Filename: "/dev/dm-1", Type: "partition", Size: 8388604, Used: 1024, Priority: -2},
+		{Filename: "/dev/zram0", Type: "partition", Size: 4194300, Used: 512, Priority: 100},
+		{Filename: "/swapfile", Type: "file", Size: 1048572, Used: 0, Priority: -3},
+	}
+	if err != nil || !reflect.DeepEqual(swaps, want) {
+		t.Errorf("GetSwaps() = %+v, %v; want %+v", swaps, err, want)
 	}
-	fmt.Printf("GetSwapFilename(): %v, err: %v\n", sf, err)
 
-	st, err := GetSwapType()
-	if err != nil {
-		t.Errorf("%v", err)
+	size, used := SwapTotals(swaps)
+	if size != 13631476 || used != 1536 {
+		t.Errorf("SwapTotals() = %v, %v; want 13631476, 1536", size, used)
 	}
-	fmt.Printf("GetSwapType(): %v, err: %v\n", st, err)
 
-	ss, err := GetSwapSize()
-	if err != nil {
-		t.Errorf("%v", err)
+	noSwap := NewFSFromFS(fstest.MapFS{"swaps": {Data: []byte("Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n")}})
+	if _, err := noSwap.GetSwaps(); !errors.Is(err, ErrNoSwap) {
+		t.Errorf("GetSwaps() without swap = %v; want ErrNoSwap", err)
+	}
+	if _, err := noSwap.GetSwapSize(); !errors.Is(err, ErrNoSwap) {
+		t.Errorf("GetSwapSize() without swap = %v; want ErrNoSwap", err)
 	}
-	fmt.Printf("GetSwapSize(): %v, err: %v\n", ss, err)
 
-	su, err := GetSwapUsed()
-	if err != nil {
-		t.Errorf("%v", err)
+	sf, err := fs.GetSwapFilename()
+	if err != nil || sf != "/dev/dm-1" {
+		t.Errorf("GetSwapFilename() = %v, %v; want /dev/dm-1", sf, err)
 	}
-	fmt.Printf("GetSwapUsed(): %v, err: %v\n", su, err)
 
-	sp, err := GetSwapPriority()
-	if err != nil {
-		t.Errorf("%v", err)
+	st, err := fs.GetSwapType()
+	if err != nil || st != "partition" {
+		t.Errorf("GetSwapType() = %v, %v; want partition", st, err)
+	}
+
+	ss, err := fs.GetSwapSize()
+	if err != nil || ss != 8388604 {
+		t.Errorf("GetSwapSize() = %v, %v; want 8388604", ss, err)
+	}
+
+	su, err := fs.GetSwapUsed()
+	if err != nil || su != 1024 {
+		t.Errorf("GetSwapUsed() = %v, %v; want 1024", su, err)
+	}
+
+	sp, err := fs.GetSwapPriority()
+	if err != nil || sp != -2 {
+		t.Errorf("GetSwapPriority() = %v, %v; want -2", sp, err)
 	}
-	fmt.Printf("GetSwapPriority(): %v, err: %v\n", sp, err)
 }
 
-//	TestUptime tests all functions that get data from /proc/uptime.
+// TestUptime tests all functions that get data from /proc/uptime.
 func TestUptime(t *testing.T) {
-	us, err := GetUptimeSystem()
-	if err != nil {
-		t.Errorf("%v", err)
+	fs := NewFSFromFS(testProcFS)
+
+	us, err := fs.GetUptimeSystem()
+	if err != nil || us != 350.5 {
+		t.Errorf("GetUptimeSystem() = %v, %v; want 350.5", us, err)
 	}
-	fmt.Printf("GetUptimeSystem(): %v, err: %v\n", us, err)
 
-	ui, err := GetUptimeIdle()
-	if err != nil {
-		t.Errorf("%v", err)
+	ui, err := fs.GetUptimeIdle()
+	if err != nil || ui != 690.25 {
+		t.Errorf("GetUptimeIdle() = %v, %v; want 690.25", ui, err)
 	}
-	fmt.Printf("GetUptimeIdle(): %v, err: %v\n", ui, err)
 }
 
-//	TestStat tests all functions that get data from /proc/stat.
+// TestStat tests all functions that get data from /proc/stat.
 func TestStat(t *testing.T) {
-	ut, err := GetCpuUserTime()
-	if err != nil {
-		t.Errorf("%v", err)
+	fs := NewFSFromFS(testProcFS)
+
+	st, err := fs.GetStat()
+	if err != nil {
+		t.Fatalf("GetStat(): %v", err)
+	}
+
+	want := Stat{
+		CPU: CPUTimes{ID: -1, User: 3672, Nice: 12, System: 618, Idle: 48101, Iowait: 822, Irq: 3, Softirq: 7, Steal: 22, Guest: 5, GuestNice: 1},
+		CPUs: []CPUTimes{
+			{ID: 0, User: 2000, Nice: 10, System: 300, Idle: 24000, Iowait: 400, Irq: 2, Softirq: 4, Steal: 11, Guest: 3, GuestNice: 1},
+			{ID: 1, User: 1672, Nice: 2, System: 318, Idle: 24101, Iowait: 422, Irq: 1, Softirq: 3, Steal: 11, Guest: 2, GuestNice: 0},
+		},
+		Intr:          72198,
+		IntrCounts:    []uint64{0, 0, 0},
+		Ctxt:          164395,
+		Btime:         1700000000,
+		Processes:     3620,
+		ProcsRunning:  1,
+		ProcsBlocked:  2,
+		Softirq:       41066,
+		SoftirqCounts: []uint64{0, 2851, 0, 2024, 0, 0, 1, 8533, 0, 27657},
+	}
+	if !reflect.DeepEqual(st, want) {
+		t.Errorf("GetStat() = %+v; want %+v", st, want)
+	}
+
+	// Kernels before 2.6.33 have fewer cpu columns.
+	old := NewFSFromFS(fstest.MapFS{"stat": {Data: []byte("cpu   10 20 30 40 50 60 70\ncpu0  10 20 30 40 50 60 70\n")}})
+	st, err = old.GetStat()
+	if err != nil || st.CPU.Softirq != 70 || st.CPU.Steal != 0 || len(st.CPUs) != 1 {
+		t.Errorf("GetStat() on an older kernel = %+v, %v", st, err)
+	}
+
+	tests := []struct {
+		name string
+		fn   func() (int, error)
+		want int
+	}{
+		{"GetCpuUserTime", fs.GetCpuUserTime, 3672},
+		{"GetCpuNiceTime", fs.GetCpuNiceTime, 12},
+		{"GetCpuSystemTime", fs.GetCpuSystemTime, 618},
+		{"GetCpuIdleTime", fs.GetCpuIdleTime, 48101},
+		{"GetCpuIowaitTime", fs.GetCpuIowaitTime, 822},
+		{"GetCpuIrqTime", fs.GetCpuIrqTime, 3},
+		{"GetCpuSoftirqTime", fs.GetCpuSoftirqTime, 7},
+		{"GetCpuStealTime", fs.GetCpuStealTime, 22},
+		{"GetCpuGuestTime", fs.GetCpuGuestTime, 5},
+		{"GetCpuGuestNiceTime", fs.GetCpuGuestNiceTime, 1},
+		{"GetProcessesBlockedSize", fs.GetProcessesBlockedSize, 2},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.fn()
+		if err != nil || got != tt.want {
+			t.Errorf("%v() = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
 	}
-	fmt.Printf("GetCpuUserTime(): %v, err: %v\n", ut, err)
+}
 
-	nt, err := GetCpuNiceTime()
-	if err != nil {
-		t.Errorf("%v", err)
-	}
-	fmt.Printf("GetCpuNiceTime(): %v, err: %v\n", nt, err)
+// TestPerProcess tests all functions that get data from /proc/<pid>/.
+func TestPerProcess(t *testing.T) {
+	fs := NewFSFromFS(testProcFS)
 
-	syst, err := GetCpuSystemTime()
+	ppStat, err := fs.GetPerProcessStat()
 	if err != nil {
-		t.Errorf("%v", err)
+		t.Fatalf("GetPerProcessStat(): %v", err)
 	}
-	fmt.Printf("GetCpuSystemTime(): %v, err: %v\n", syst, err)
-
-	it, err := GetCpuIdleTime()
-	if err != nil {
-		t.Errorf("%v", err)
+	if len(ppStat) != 2 || ppStat[0].Pid != 1 || ppStat[1].Pid != 42 {
+		t.Errorf("GetPerProcessStat() = %v; want PIDs 1 and 42", ppStat)
 	}
-	fmt.Printf("GetCpuIdleTime(): %v, err: %v\n", it, err)
 
-	iot, err := GetCpuIowaitTime()
+	ps, err := fs.GetProcessStat(1)
 	if err != nil {
-		t.Errorf("%v", err)
+		t.Fatalf("GetProcessStat(1): %v", err)
 	}
-	fmt.Printf("GetCpuIowaitTime(): %v, err: %v\n", iot, err)
-
-	irqt, err := GetCpuIrqTime()
-	if err != nil {
-		t.Errorf("%v", err)
+	if ps.Comm != "systemd" || ps.State != "S" || ps.Ppid != 0 || ps.Minflt != 46427 ||
+		ps.Utime != 122 || ps.Stime != 285 || ps.Starttime != 27 || ps.Vsize != 172404736 ||
+		ps.Rss != 3199 || ps.ExitSignal != 17 || ps.ExitCode != 0 {
+		t.Errorf("GetProcessStat(1) = %+v", ps)
 	}
-	fmt.Printf("GetCpuIrqTime(): %v, err: %v\n", irqt, err)
 
-	sirqt, err := GetCpuSoftirqTime()
-	if err != nil {
-		t.Errorf("%v", err)
+	ps, err = fs.GetProcessStat(42)
+	if err != nil || ps.Comm != "kworker/0:1-events" || ps.Ppid != 2 {
+		t.Errorf("GetProcessStat(42) = %+v, %v", ps, err)
 	}
-	fmt.Printf("GetCpuSoftirqTime(): %v, err: %v\n", sirqt, err)
 
-	st, err := GetCpuStealTime()
-	if err != nil {
-		t.Errorf("%v", err)
+	if _, err := fs.GetProcessStat(7); err == nil {
+		t.Errorf("GetProcessStat(7) of a missing process returned no error")
 	}
-	fmt.Printf("GetCpuStealTime(): %v, err: %v\n", st, err)
+}
 
-	gt, err := GetCpuGuestTime()
-	if err != nil {
-		t.Errorf("%v", err)
+// TestParseProcstat tests parsing /proc/<pid>/stat with unusual comms and field counts.
+func TestParseProcstat(t *testing.T) {
+	tests := []struct {
+		name string
+		dat  string
+		want Procstat
+	}{
+		{
+			name: "comm with spaces",
+			dat:  "1234 (tmux: server) S 1 1234 1234 0 -1 4194368 1541 0 0 0 11 6 0 0 20 0 1 0 3513 9621504 1040 18446744073709551615 1 1 0 0 0 0 0 3674112 134433283 0 0 0 17 3 0 0 0 0 0 0 0 0 0 0 0 0 0\n",
+			want: Procstat{Pid: 1234, Comm: "tmux: server", State: "S", Ppid: 1, Pgrp: 1234, Session: 1234, Tpgid: -1,
+				Flags: 4194368, Minflt: 1541, Utime: 11, Stime: 6, Priority: 20, NumThreads: 1, Starttime: 3513,
+				Vsize: 9621504, Rss: 1040, Rsslim: 18446744073709551615, Startcode: 1, Endcode: 1,
+				Sigignore: 3674112, Sigcatch: 134433283, ExitSignal: 17, Processor: 3, NumFields: 52},
+		},
+		{
+			name: "comm with parentheses",
+			dat:  "77 (a) (b)) R 1 77 77 0 -1 0 0 0 0 0 0 0 0 0 -2 -10 1 0 42 0 0 18446744073709551615 0 0 0 0 0 0 0 0 0 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n",
+			want: Procstat{Pid: 77, Comm: "a) (b)", State: "R", Ppid: 1, Pgrp: 77, Session: 77, Tpgid: -1, Priority: -2, Nice: -10,
+				NumThreads: 1, Starttime: 42, Rsslim: 18446744073709551615, ExitSignal: 17, NumFields: 52},
+		},
+		{
+			name: "older kernel with 44 fields",
+			dat:  "9 (init) S 0 9 9 0 -1 256 10 20 30 40 5 6 7 8 20 0 1 0 12 4096 3 4294967295 1 2 3 4 5 0 0 0 0 0 0 0 17 1 0 0 99 11 12\n",
+			want: Procstat{Pid: 9, Comm: "init", State: "S", Pgrp: 9, Session: 9, Tpgid: -1, Flags: 256, Minflt: 10,
+				Cminflt: 20, Majflt: 30, Cmajflt: 40, Utime: 5, Stime: 6, Cutime: 7, Cstime: 8, Priority: 20, NumThreads: 1,
+				Starttime: 12, Vsize: 4096, Rss: 3, Rsslim: 4294967295, Startcode: 1, Endcode: 2, Startstack: 3, Kstkesp: 4,
+				Kstkeip: 5, ExitSignal: 17, Processor: 1, DelayacctBlkioTicks: 99, GuestTime: 11, CguestTime: 12, NumFields: 44},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := parseProcstat("1/stat", []byte(tt.dat))
+		if err != nil {
+			t.Errorf("%v: parseProcstat(): %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: parseProcstat() = %+v; want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, dat := range []string{"", "1 systemd S 0", "1 (systemd)", "x (systemd) S 0"} {
+		if _, err := parseProcstat("1/stat", []byte(dat)); err == nil {
+			t.Errorf("parseProcstat(%q) returned no error", dat)
+		}
 	}
-	fmt.Printf("GetCpuGuestTime(): %v, err: %v\n", gt, err)
+}
 
-	gnt, err := GetCpuGuestNiceTime()
-	if err != nil {
-		t.Errorf("%v", err)
-	}
-	fmt.Printf("GetCpuGuestNiceTime(): %v, err: %v\n", gnt, err)
-/*
-	blksz, err := GetProcessesBlockedSize()
-	if err != nil {
-		t.Errorf("%v", err)
+// TestMeminfo tests all functions that get from /proc/meminfo
+func TestMeminfo(t *testing.T) {
+	fs := NewFSFromFS(testProcFS)
+
+	m, err := fs.GetMeminfo()
+	if err != nil {
+		t.Fatalf("GetMeminfo(): %v", err)
+	}
+
+	want := Meminfo{
+		MemTotal:       6158152 * 1024,
+		MemFree:        5160632 * 1024,
+		MemAvailable:   5693716 * 1024,
+		Buffers:        58072 * 1024,
+		Cached:         678316 * 1024,
+		Active:         551052 * 1024,
+		ActiveAnon:     334680 * 1024,
+		SwapTotal:      13631476 * 1024,
+		Dirty:          128 * 1024,
+		Shmem:          4784 * 1024,
+		Slab:           71932 * 1024,
+		SReclaimable:   44404 * 1024,
+		CommittedAS:    1462780 * 1024,
+		HugePagesTotal: 4,
+		Hugepagesize:   2048 * 1024,
+		Other:          map[string]uint64{"FutureField": 16 * 1024, "FutureCount": 3},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("GetMeminfo() = %+v; want %+v", m, want)
+	}
+
+	tests := []struct {
+		name string
+		fn   func() (int, error)
+		want int
+	}{
+		{"GetMemTotal", fs.GetMemTotal, 6158152},
+		{"GetMemFree", fs.GetMemFree, 5160632},
+		{"GetMemUsed", fs.GetMemUsed, 997520},
+		{"GetMemAvailable", fs.GetMemAvailable, 5693716},
+		{"GetMemBuffers", fs.GetMemBuffers, 58072},
+		{"GetMemCached", fs.GetMemCached, 678316},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.fn()
+		if err != nil || got != tt.want {
+			t.Errorf("%v() = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
 	}
-	fmt.Printf("GetProcessesBlockedSize(): %v, err: %v\n", blksz, err)
-*/
 }
 
-// 	TestPerProcess tests all functions that get data from /proc/<pid>/.
-func TestPerProcess(t *testing.T) {
-	ppStat, err := GetPerProcessStat()
-	if err != nil {
-		t.Errorf("%#v", err)
-	}
-	fmt.Printf("GetPerProcessStat(): %v, err: %v\n", ppStat, err)
+// TestSysKernel tests all functions that get data from /proc/sys/kernel.
+func TestSysKernel(t *testing.T) {
+	fs := NewFSFromFS(testProcFS)
 
-	ps, err := GetProcessStat(1)	
-	if err != nil {
-		t.Errorf("%#v", err)
+	kr, err := fs.GetKernelRelease()
+	if err != nil || kr != "6.1.0-13-amd64\n" {
+		t.Errorf("GetKernelRelease() = %q, %v", kr, err)
 	}
-	fmt.Printf("GetProcessStat(1): %v, err: %v\n", ps, err)
 }
 
-//	TestMeminfo tests all functions that get from /proc/meminfo
-func TestMeminfo(t *testing.T) {
-
-	mt, err := GetMemTotal()
+// TestNewFS tests reading procfs data through a FS handle on the live /proc.
+func TestNewFS(t *testing.T) {
+	fs, err := NewFS("/proc")
 	if err != nil {
-		t.Errorf("%v", err)
+		t.Fatalf("%v", err)
 	}
-	fmt.Printf("GetMemTotal(): %v, err: %v\n", mt, err)
 
-	mf, err := GetMemFree()
-	if err != nil {
-		t.Errorf("%v", err)
+	if _, err := fs.GetLoadAverage1(); err != nil {
+		t.Errorf("GetLoadAverage1(): %v", err)
 	}
-	fmt.Printf("GetMemFree(): %v, err: %v\n", mf, err)
 
-	mu, err := GetMemUsed()
-	if err != nil {
-		t.Errorf("%v", err)
+	if _, err := fs.GetProcessStat(1); err != nil {
+		t.Errorf("GetProcessStat(1): %v", err)
 	}
-	fmt.Printf("GetMemUsed(): %v, err: %v\n", mu, err)
 
-	ma, err := GetMemAvailable()
-	if err != nil {
-		t.Errorf("%v", err)
+	if _, err := NewFS("/nonexistent/proc"); err == nil {
+		t.Errorf("NewFS() of a missing directory returned no error")
 	}
-	fmt.Printf("GetMemAvailable(): %v, err: %v\n", ma, err)
+}
 
-	mb, err := GetMemBuffers()
-	if err != nil {
-		t.Errorf("%v", err)
+// TestZeroFS tests that the zero FS reads the default /proc.
+func TestZeroFS(t *testing.T) {
+	var fs FS
+
+	if fs.Root() != procdir {
+		t.Errorf("Root() = %q; want %q", fs.Root(), procdir)
 	}
-	fmt.Printf("GetMemBuffers(): %v, err: %v\n", mb, err)
 
-	mc, err := GetMemCached()
-	if err != nil {
-		t.Errorf("%v", err)
+	if _, err := fs.GetLoadavg(); err != nil {
+		t.Errorf("GetLoadavg(): %v", err)
 	}
-	fmt.Printf("GetMemCached(): %v, err: %v\n", mc, err)
-}
 
-//	TestSysKernel tests all functions that get data from /proc/sys/kernel.
-func TestSysKernel(t *testing.T) {
-	kr , err := GetKernelRelease()
-	if err != nil {
-		t.Errorf("%v", err)
+	if _, err := fs.GetProcessStat(os.Getpid()); err != nil {
+		t.Errorf("GetProcessStat(%v): %v", os.Getpid(), err)
 	}
-	fmt.Printf("GetKernelRelease(): %v, err: %v\n", kr, err)
 }
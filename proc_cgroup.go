@@ -0,0 +1,242 @@
+package lpfs
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+const procdir_per_process_cgroup string = "cgroup"
+
+// ProcCgroup contains a cgroup membership of a process, available in /proc/<pid>/cgroup.
+type ProcCgroup struct {
+	// HierarchyID is the cgroup v1 hierarchy, or 0 for the cgroup v2
+	// unified hierarchy.
+	HierarchyID int
+
+	// Controllers are the cgroup v1 controllers bound to the hierarchy,
+	// e.g. "cpu" and "cpuacct", or "name=systemd" for named hierarchies.
+	// It is empty for cgroup v2.
+	Controllers []string
+
+	// Path is the cgroup relative to the hierarchy root, or to the cgroup
+	// namespace root of the reader, e.g. "/system.slice/sshd.service".
+	Path string
+}
+
+// GetProcessCgroups returns the cgroups of a giving process.
+func GetProcessCgroups(pid int) ([]ProcCgroup, error) {
+	return defaultFS.GetProcessCgroups(pid)
+}
+
+// GetProcessCgroups returns the cgroups of a giving process, one per
+// cgroup v1 hierarchy and one for cgroup v2, in the order of the kernel.
+func (pfs FS) GetProcessCgroups(pid int) ([]ProcCgroup, error) {
+	cgroupFile := path.Join(strconv.Itoa(pid), procdir_per_process_cgroup)
+
+	dat, err := pfs.readFile(cgroupFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var cgroups []ProcCgroup
+
+	for n, line := range strings.Split(string(dat), "\n") {
+		if line == "" {
+			continue
+		}
+
+		// e.g. "4:memory:/docker/3f4e..." or "0::/user.slice"; the path may
+		// contain ':'.
+		dat_s := strings.SplitN(line, ":", 3)
+		if len(dat_s) != 3 {
+			return nil, &ParseError{File: cgroupFile, Line: n + 1, Err: errUnexpectedFormat(line)}
+		}
+
+		id, err := strconv.Atoi(dat_s[0])
+		if err != nil {
+			return nil, &ParseError{File: cgroupFile, Field: "hierarchy-ID", Line: n + 1, Err: err}
+		}
+
+		c := ProcCgroup{HierarchyID: id, Path: dat_s[2]}
+		if dat_s[1] != "" {
+			c.Controllers = strings.Split(dat_s[1], ",")
+		}
+
+		cgroups = append(cgroups, c)
+	}
+
+	return cgroups, nil
+}
+
+// Kubernetes pod QoS classes, as in CgroupInfo.QoSClass.
+const (
+	QoSGuaranteed = "Guaranteed"
+	QoSBurstable  = "Burstable"
+	QoSBestEffort = "BestEffort"
+)
+
+// CgroupInfo contains the container and service identity of a process,
+// as resolved from its cgroup paths.
+type CgroupInfo struct {
+	ContainerID string // 64 hex digit container ID
+	Runtime     string // "docker", "containerd", "cri-o" or "podman", if known
+	PodUID      string // Kubernetes pod UID, e.g. "0b3c2a7e-5c3e-4d4a-9a8e-1f2d3c4b5a69"
+	QoSClass    string // Kubernetes QoS class of the pod, e.g. QoSBurstable
+	Unit        string // innermost systemd unit, e.g. "sshd.service"
+	Slice       string // innermost systemd slice, e.g. "system.slice"
+}
+
+// containerPrefixes are the prefixes of the systemd scopes of container
+// runtimes, e.g. "docker-<id>.scope", and the runtimes they belong to.
+var containerPrefixes = []struct {
+	prefix  string
+	runtime string
+}{
+	{"docker-", "docker"},
+	{"cri-containerd-", "containerd"},
+	{"crio-", "cri-o"},
+	{"libpod-", "podman"},
+}
+
+// ResolveCgroupPath returns the container and service identity found in a
+// cgroup path, for the paths created by the systemd and cgroupfs drivers of
+// Docker, containerd, CRI-O, Podman and the kubelet.
+// Fields that cannot be resolved are left empty, e.g. for a process in a
+// cgroup namespace, whose path is "/".
+func ResolveCgroupPath(p string) CgroupInfo {
+	var info CgroupInfo
+	var kubepods bool
+
+	elems := strings.Split(strings.Trim(p, "/"), "/")
+
+	for i, e := range elems {
+		switch {
+		case e == "kubepods" || e == "kubepods.slice":
+			kubepods = true
+		case kubepods && (e == "burstable" || e == "kubepods-burstable.slice"):
+			info.QoSClass = QoSBurstable
+		case kubepods && (e == "besteffort" || e == "kubepods-besteffort.slice"):
+			info.QoSClass = QoSBestEffort
+		}
+
+		// e.g. "pod0b3c2a7e-..." or "kubepods-burstable-pod0b3c2a7e_....slice"
+		if kubepods {
+			if uid := podUID(e); uid != "" {
+				info.PodUID = uid
+			}
+		}
+
+		if id, runtime := containerID(e); id != "" {
+			info.ContainerID, info.Runtime = id, runtime
+			if runtime == "" && i > 0 && elems[i-1] == "docker" {
+				info.Runtime = "docker"
+			}
+		}
+
+		switch {
+		case strings.HasSuffix(e, ".slice"):
+			info.Slice = e
+		case strings.HasSuffix(e, ".service") || strings.HasSuffix(e, ".scope"):
+			info.Unit = e
+		}
+	}
+
+	// Guaranteed pods are placed directly under kubepods.
+	if info.PodUID != "" && info.QoSClass == "" {
+		info.QoSClass = QoSGuaranteed
+	}
+
+	return info
+}
+
+// ResolveCgroups returns the container and service identity of a process
+// from its cgroups. Each field is taken from the first cgroup that resolves
+// it, with cgroup v2 first.
+func ResolveCgroups(cgroups []ProcCgroup) CgroupInfo {
+	var info CgroupInfo
+
+	merge := func(c ProcCgroup) {
+		r := ResolveCgroupPath(c.Path)
+
+		if info.ContainerID == "" {
+			info.ContainerID, info.Runtime = r.ContainerID, r.Runtime
+		}
+		if info.PodUID == "" {
+			info.PodUID, info.QoSClass = r.PodUID, r.QoSClass
+		}
+		if info.Unit == "" {
+			info.Unit = r.Unit
+		}
+		if info.Slice == "" {
+			info.Slice = r.Slice
+		}
+	}
+
+	for _, c := range cgroups {
+		if c.HierarchyID == 0 {
+			merge(c)
+		}
+	}
+	for _, c := range cgroups {
+		if c.HierarchyID != 0 {
+			merge(c)
+		}
+	}
+
+	return info
+}
+
+// containerID returns the container ID and runtime of a cgroup path element,
+// e.g. "docker-<id>.scope" or "<id>", or "" if it is not a container.
+func containerID(e string) (string, string) {
+	if isContainerID(e) {
+		return e, ""
+	}
+
+	for _, c := range containerPrefixes {
+		if !strings.HasPrefix(e, c.prefix) || !strings.HasSuffix(e, ".scope") {
+			continue
+		}
+
+		id := strings.TrimSuffix(strings.TrimPrefix(e, c.prefix), ".scope")
+		if isContainerID(id) {
+			return id, c.runtime
+		}
+	}
+
+	return "", ""
+}
+
+// isContainerID reports whether s is a 64 hex digit container ID.
+func isContainerID(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// podUID returns the pod UID of a kubelet cgroup path element, e.g.
+// "pod<uid>" or "kubepods-besteffort-pod<uid with _>.slice", or "".
+func podUID(e string) string {
+	e = strings.TrimSuffix(e, ".slice")
+
+	i := strings.LastIndex(e, "pod")
+	if i < 0 || (i > 0 && e[i-1] != '-') {
+		return ""
+	}
+
+	uid := strings.ReplaceAll(e[i+len("pod"):], "_", "-")
+	if len(uid) != 36 || strings.Count(uid, "-") != 4 {
+		return ""
+	}
+
+	return uid
+}
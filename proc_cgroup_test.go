@@ -0,0 +1,108 @@
+package lpfs
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+const testContainerID = "3f4e9b2c1d0a8f7e6d5c4b3a29180f7e6d5c4b3a29180f7e6d5c4b3a29180f7e"
+
+// TestGetProcessCgroups tests parsing /proc/<pid>/cgroup of cgroup v1 and v2.
+func TestGetProcessCgroups(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"1/cgroup": {Data: []byte("12:name=systemd:/system.slice/docker-" + testContainerID + ".scope\n" +
+			"4:cpu,cpuacct:/system.slice/docker-" + testContainerID + ".scope\n" +
+			"1:memory:/odd:path\n" +
+			"0::/system.slice/docker-" + testContainerID + ".scope\n")},
+		"2/cgroup": {Data: []byte("0::/user.slice/user-1000.slice/session-2.scope\n")},
+		"3/cgroup": {Data: []byte("0:/\n")},
+	})
+
+	cgroups, err := fs.GetProcessCgroups(1)
+	want := []ProcCgroup{
+		{HierarchyID: 12, Controllers: []string{"name=systemd"}, Path: "/system.slice/docker-" + testContainerID + ".scope"},
+		{HierarchyID: 4, Controllers: []string{"cpu", "cpuacct"}, Path: "/system.slice/docker-" + testContainerID + ".scope"},
+		{HierarchyID: 1, Controllers: []string{"memory"}, Path: "/odd:path"},
+		{HierarchyID: 0, Path: "/system.slice/docker-" + testContainerID + ".scope"},
+	}
+	if err != nil || !reflect.DeepEqual(cgroups, want) {
+		t.Errorf("GetProcessCgroups(1) = %+v, %v; want %+v", cgroups, err, want)
+	}
+
+	cgroups, err = fs.GetProcessCgroups(2)
+	want = []ProcCgroup{{Path: "/user.slice/user-1000.slice/session-2.scope"}}
+	if err != nil || !reflect.DeepEqual(cgroups, want) {
+		t.Errorf("GetProcessCgroups(2) = %+v, %v; want %+v", cgroups, err, want)
+	}
+
+	var perr *ParseError
+	if _, err := fs.GetProcessCgroups(3); !errors.As(err, &perr) || perr.Line != 1 {
+		t.Errorf("GetProcessCgroups(3) = %v; want ParseError for line 1", err)
+	}
+}
+
+// TestResolveCgroupPath tests resolving the paths of the common container
+// runtimes, the kubelet and systemd.
+func TestResolveCgroupPath(t *testing.T) {
+	const uid = "0b3c2a7e-5c3e-4d4a-9a8e-1f2d3c4b5a69"
+	const uidSystemd = "0b3c2a7e_5c3e_4d4a_9a8e_1f2d3c4b5a69"
+
+	tests := []struct {
+		path string
+		want CgroupInfo
+	}{
+		{"/", CgroupInfo{}},
+		{"/system.slice/sshd.service", CgroupInfo{Slice: "system.slice", Unit: "sshd.service"}},
+		{"/user.slice/user-1000.slice/user@1000.service/app.slice/app-firefox-1234.scope",
+			CgroupInfo{Slice: "app.slice", Unit: "app-firefox-1234.scope"}},
+		{"/docker/" + testContainerID, CgroupInfo{ContainerID: testContainerID, Runtime: "docker"}},
+		{"/system.slice/docker-" + testContainerID + ".scope",
+			CgroupInfo{ContainerID: testContainerID, Runtime: "docker", Slice: "system.slice", Unit: "docker-" + testContainerID + ".scope"}},
+		{"/machine.slice/libpod-" + testContainerID + ".scope/container",
+			CgroupInfo{ContainerID: testContainerID, Runtime: "podman", Slice: "machine.slice", Unit: "libpod-" + testContainerID + ".scope"}},
+		{"/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod" + uidSystemd + ".slice/cri-containerd-" + testContainerID + ".scope",
+			CgroupInfo{ContainerID: testContainerID, Runtime: "containerd", PodUID: uid, QoSClass: QoSBurstable,
+				Slice: "kubepods-burstable-pod" + uidSystemd + ".slice", Unit: "cri-containerd-" + testContainerID + ".scope"}},
+		{"/kubepods.slice/kubepods-pod" + uidSystemd + ".slice/crio-" + testContainerID + ".scope",
+			CgroupInfo{ContainerID: testContainerID, Runtime: "cri-o", PodUID: uid, QoSClass: QoSGuaranteed,
+				Slice: "kubepods-pod" + uidSystemd + ".slice", Unit: "crio-" + testContainerID + ".scope"}},
+		{"/kubepods/besteffort/pod" + uid + "/" + testContainerID,
+			CgroupInfo{ContainerID: testContainerID, PodUID: uid, QoSClass: QoSBestEffort}},
+		{"/kubepods/pod" + uid, CgroupInfo{PodUID: uid, QoSClass: QoSGuaranteed}},
+		{"/system.slice/podman.service", CgroupInfo{Slice: "system.slice", Unit: "podman.service"}},
+	}
+
+	for _, tt := range tests {
+		if info := ResolveCgroupPath(tt.path); info != tt.want {
+			t.Errorf("ResolveCgroupPath(%q) = %+v; want %+v", tt.path, info, tt.want)
+		}
+	}
+}
+
+// TestResolveCgroups tests that cgroup v2 takes precedence over the v1 hierarchies.
+func TestResolveCgroups(t *testing.T) {
+	info := ResolveCgroups([]ProcCgroup{
+		{HierarchyID: 2, Controllers: []string{"name=systemd"}, Path: "/system.slice/cron.service"},
+		{HierarchyID: 1, Controllers: []string{"memory"}, Path: "/docker/" + testContainerID},
+		{HierarchyID: 0, Path: "/system.slice/containerd.service"},
+	})
+
+	want := CgroupInfo{ContainerID: testContainerID, Runtime: "docker", Slice: "system.slice", Unit: "containerd.service"}
+	if info != want {
+		t.Errorf("ResolveCgroups() = %+v; want %+v", info, want)
+	}
+}
+
+// TestGetProcessCgroupsLive tests reading the cgroups of the running process.
+func TestGetProcessCgroupsLive(t *testing.T) {
+	cgroups, err := GetProcessCgroups(os.Getpid())
+	if errors.Is(err, ErrNotSupported) {
+		t.Skip("kernel without cgroups")
+	}
+	if err != nil || len(cgroups) == 0 {
+		t.Errorf("GetProcessCgroups(%v) = %+v, %v", os.Getpid(), cgroups, err)
+	}
+}
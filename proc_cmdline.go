@@ -0,0 +1,75 @@
+package lpfs
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	procdir_per_process_cmdline string = "cmdline"
+	procdir_per_process_environ string = "environ"
+)
+
+// GetProcessCmdline returns the command line arguments of a giving process.
+func GetProcessCmdline(pid int) ([]string, error) {
+	return defaultFS.GetProcessCmdline(pid)
+}
+
+// GetProcessCmdline returns the command line arguments of a giving process.
+// Unlike Procstat.Comm, the arguments are not truncated. Kernel threads and
+// zombies have no command line and return an empty slice.
+//
+// A process may rewrite its command line, e.g. as a single string of
+// space-separated words, which is returned as is.
+func (pfs FS) GetProcessCmdline(pid int) ([]string, error) {
+	dat, err := pfs.readFile(strconv.Itoa(pid), procdir_per_process_cmdline)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitNul(dat), nil
+}
+
+// GetProcessEnviron returns the initial environment of a giving process.
+func GetProcessEnviron(pid int) (map[string]string, error) {
+	return defaultFS.GetProcessEnviron(pid)
+}
+
+// GetProcessEnviron returns the initial environment of a giving process.
+// Changes made by the process to its environment after it started are not
+// reflected. Only the owner of a process (or a privileged user) may read
+// its environment; other processes return an error matching ErrPermission.
+//
+// Entries without "=" map to an empty value. If a variable is set more
+// than once, the first value is returned, as getenv(3) does.
+func (pfs FS) GetProcessEnviron(pid int) (map[string]string, error) {
+	dat, err := pfs.readFile(strconv.Itoa(pid), procdir_per_process_environ)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string)
+
+	for _, kv := range splitNul(dat) {
+		k, v := kv, ""
+		if i := strings.Index(kv, "="); i >= 0 {
+			k, v = kv[:i], kv[i+1:]
+		}
+
+		if _, ok := env[k]; !ok {
+			env[k] = v
+		}
+	}
+
+	return env, nil
+}
+
+// splitNul splits a list of NUL-terminated strings, e.g. "a\x00b\x00".
+func splitNul(dat []byte) []string {
+	s := strings.TrimSuffix(string(dat), "\x00")
+	if s == "" {
+		return []string{}
+	}
+
+	return strings.Split(s, "\x00")
+}
@@ -0,0 +1,103 @@
+package lpfs
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// testCmdlineFS is a procfs fixture with a regular process (1), a kernel
+// thread (2), a process that rewrote its command line (10) and a process of
+// another user (20).
+var testCmdlineFS = fstest.MapFS{
+	"1/stat":     {Data: testScanFS["1/stat"].Data},
+	"1/cmdline":  {Data: []byte("/usr/lib/jvm/bin/java\x00-Xmx2g\x00-jar\x00app server.jar\x00")},
+	"1/environ":  {Data: []byte("HOME=/root\x00PATH=/usr/bin:/bin\x00EMPTY=\x00NOVALUE\x00OPTS=a=b\x00HOME=/tmp\x00")},
+	"2/stat":     {Data: testScanFS["2/stat"].Data},
+	"2/cmdline":  {Data: []byte{}},
+	"2/environ":  {Data: []byte{}},
+	"10/stat":    {Data: testScanFS["10/stat"].Data},
+	"10/cmdline": {Data: []byte("postgres: checkpointer   ")},
+	"20/stat":    {Data: []byte("20 (sshd) S 1 20 20 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 1 0 90 0 0 18446744073709551615 0 0 0 0 0 0 0 0 0 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"20/cmdline": {Data: []byte("sshd: admin [priv]\x00")},
+	"20/environ": {Data: []byte("SECRET=1\x00")},
+}
+
+// TestGetProcessCmdline tests splitting /proc/<pid>/cmdline into arguments.
+func TestGetProcessCmdline(t *testing.T) {
+	fs := NewFSFromFS(testCmdlineFS)
+
+	tests := []struct {
+		pid  int
+		want []string
+	}{
+		{1, []string{"/usr/lib/jvm/bin/java", "-Xmx2g", "-jar", "app server.jar"}},
+		{2, []string{}},
+		{10, []string{"postgres: checkpointer   "}},
+	}
+
+	for _, tt := range tests {
+		args, err := fs.GetProcessCmdline(tt.pid)
+		if err != nil || !reflect.DeepEqual(args, tt.want) {
+			t.Errorf("GetProcessCmdline(%v) = %q, %v; want %q", tt.pid, args, err, tt.want)
+		}
+	}
+}
+
+// TestGetProcessEnviron tests parsing /proc/<pid>/environ.
+func TestGetProcessEnviron(t *testing.T) {
+	fs := NewFSFromFS(deniedFS{FS: testCmdlineFS, name: "20/environ"})
+
+	env, err := fs.GetProcessEnviron(1)
+	want := map[string]string{"HOME": "/root", "PATH": "/usr/bin:/bin", "EMPTY": "", "NOVALUE": "", "OPTS": "a=b"}
+	if err != nil || !reflect.DeepEqual(env, want) {
+		t.Errorf("GetProcessEnviron(1) = %v, %v; want %v", env, err, want)
+	}
+
+	env, err = fs.GetProcessEnviron(2)
+	if err != nil || len(env) != 0 {
+		t.Errorf("GetProcessEnviron(2) = %v, %v; want an empty environment", env, err)
+	}
+
+	if _, err := fs.GetProcessEnviron(20); !errors.Is(err, ErrPermission) {
+		t.Errorf("GetProcessEnviron(20) = %v; want %v", err, ErrPermission)
+	}
+}
+
+// TestGetPerProcessStatCmdline tests that the per-process scan reads the
+// command lines only when asked to.
+func TestGetPerProcessStatCmdline(t *testing.T) {
+	fs := NewFSFromFS(testCmdlineFS)
+
+	pps, err := fs.GetPerProcessStatWithOptions(StatScanOptions{ScanOptions: ScanOptions{Workers: 2}})
+	if err != nil || len(pps) != 4 || pps[0].Cmdline != nil {
+		t.Errorf("GetPerProcessStatWithOptions() = %+v, %v; want no command lines", pps, err)
+	}
+
+	pps, err = fs.GetPerProcessStatWithOptions(StatScanOptions{ScanOptions: ScanOptions{Workers: 2}, Cmdline: true})
+	if err != nil || len(pps) != 4 {
+		t.Fatalf("GetPerProcessStatWithOptions(Cmdline) = %+v, %v", pps, err)
+	}
+	if len(pps[0].Cmdline) != 4 || pps[0].Cmdline[1] != "-Xmx2g" || len(pps[1].Cmdline) != 0 {
+		t.Errorf("GetPerProcessStatWithOptions(Cmdline) = %+v", pps)
+	}
+}
+
+// TestGetProcessCmdlineLive tests reading the command line and environment
+// of the running process.
+func TestGetProcessCmdlineLive(t *testing.T) {
+	args, err := GetProcessCmdline(os.Getpid())
+	if err != nil || !reflect.DeepEqual(args, os.Args) {
+		t.Errorf("GetProcessCmdline(%v) = %q, %v; want %q", os.Getpid(), args, err, os.Args)
+	}
+
+	env, err := GetProcessEnviron(os.Getpid())
+	if err != nil {
+		t.Fatalf("GetProcessEnviron(%v): %v", os.Getpid(), err)
+	}
+	if path, ok := os.LookupEnv("PATH"); ok && env["PATH"] != path {
+		t.Errorf("GetProcessEnviron(%v)[PATH] = %q; want %q", os.Getpid(), env["PATH"], path)
+	}
+}
@@ -0,0 +1,268 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	procdir_per_process_fd     string = "fd"
+	procdir_per_process_fdinfo string = "fdinfo"
+)
+
+// FDType is the kind of object a file descriptor refers to.
+type FDType string
+
+const (
+	FDFile      FDType = "file"       // a path: regular file, directory, device, memfd...
+	FDSocket    FDType = "socket"     // e.g. "socket:[12345]"
+	FDPipe      FDType = "pipe"       // e.g. "pipe:[12345]"
+	FDAnonInode FDType = "anon_inode" // e.g. "anon_inode:[eventfd]"
+)
+
+// ProcFD contains an open file descriptor of a process, available in
+// /proc/<pid>/fd/<fd> and /proc/<pid>/fdinfo/<fd>.
+type ProcFD struct {
+	Pid    int
+	FD     int
+	Target string // target of the fd link, e.g. "/var/log/syslog" or "socket:[12345]"
+
+	// Type is the kind of Target. Kernel objects other than sockets and
+	// pipes use their own prefix, e.g. "net" for "net:[4026531840]".
+	Type FDType
+
+	Path     string // file path without the " (deleted)" suffix, for FDFile
+	Deleted  bool   // whether the file was deleted, for FDFile
+	Inode    uint64 // inode of the socket, pipe or other kernel object in Target
+	AnonType string // e.g. "[eventfd]" or "inotify", for FDAnonInode
+
+	Pos   uint64 // file offset
+	Flags uint64 // open(2) flags, e.g. O_WRONLY|O_APPEND
+	MntID int    // ID of the mount, as in /proc/<pid>/mountinfo
+	Ino   uint64 // inode number, since Linux 5.14
+}
+
+// GetProcessFDs returns the open file descriptors of a giving process, in ascending order.
+func GetProcessFDs(pid int) ([]ProcFD, error) {
+	return defaultFS.GetProcessFDs(pid)
+}
+
+// GetProcessFDs returns the open file descriptors of a giving process, in ascending order.
+// Only the owner of a process (or a privileged user) may list its file
+// descriptors; other processes return an error matching ErrPermission.
+// File descriptors closed while they are being read are skipped.
+//
+// The targets can only be read if the FS was created by NewFS, or if its
+// fs.FS has a ReadLink method; otherwise an error matching ErrNotSupported
+// is returned.
+func (pfs FS) GetProcessFDs(pid int) ([]ProcFD, error) {
+	return pfs.processFDs(context.Background(), pid, nil)
+}
+
+// GetProcessFDsContext is like GetProcessFDs, but stops when ctx is done.
+func GetProcessFDsContext(ctx context.Context, pid int) ([]ProcFD, error) {
+	return defaultFS.GetProcessFDsContext(ctx, pid)
+}
+
+// GetProcessFDsContext is like GetProcessFDs, but stops when ctx is done.
+// In that case, ctx.Err() is returned.
+func (pfs FS) GetProcessFDsContext(ctx context.Context, pid int) ([]ProcFD, error) {
+	return pfs.processFDs(ctx, pid, nil)
+}
+
+// processFDs reads the file descriptors of process pid whose target matches,
+// or all of them if match is nil. The fdinfo is only read for the matches.
+// It stops with ctx.Err() when ctx is done.
+func (pfs FS) processFDs(ctx context.Context, pid int, match func(f *ProcFD) bool) ([]ProcFD, error) {
+	p := strconv.Itoa(pid)
+
+	entries, err := pfs.readDir(p, procdir_per_process_fd)
+	if err != nil {
+		return nil, err
+	}
+
+	fds := make([]ProcFD, 0, len(entries))
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		fd, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+
+		f, ok, err := pfs.processFD(pid, fd, match)
+		if errors.Is(err, fs.ErrNotExist) && !isProcessGone(err) {
+			// The fd was closed since the directory was read.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		fds = append(fds, f)
+	}
+
+	sort.Slice(fds, func(i, j int) bool { return fds[i].FD < fds[j].FD })
+
+	return fds, nil
+}
+
+// processFD reads the target and fdinfo of file descriptor fd of process pid.
+// It returns false, without reading the fdinfo, if the target does not match.
+func (pfs FS) processFD(pid int, fd int, match func(f *ProcFD) bool) (ProcFD, bool, error) {
+	p, n := strconv.Itoa(pid), strconv.Itoa(fd)
+
+	target, err := pfs.readLink(p, procdir_per_process_fd, n)
+	if err != nil {
+		return ProcFD{}, false, err
+	}
+
+	f := ProcFD{Pid: pid, FD: fd, Target: target}
+	f.classifyTarget()
+
+	if match != nil && !match(&f) {
+		return ProcFD{}, false, nil
+	}
+
+	infoFile := path.Join(p, procdir_per_process_fdinfo, n)
+
+	dat, err := pfs.readFile(infoFile)
+	if err != nil {
+		return ProcFD{}, false, err
+	}
+
+	if err := f.parseFDInfo(infoFile, dat); err != nil {
+		return ProcFD{}, false, err
+	}
+
+	return f, true, nil
+}
+
+// classifyTarget sets the Type and the fields derived from the Target of f.
+func (f *ProcFD) classifyTarget() {
+	t := f.Target
+
+	switch {
+	case strings.HasPrefix(t, "/"):
+		f.Type = FDFile
+		f.Path = strings.TrimSuffix(t, " (deleted)")
+		f.Deleted = f.Path != t
+	case strings.HasPrefix(t, "anon_inode:"):
+		f.Type = FDAnonInode
+		f.AnonType = strings.TrimPrefix(t, "anon_inode:")
+	default:
+		// e.g. "socket:[12345]"
+		i := strings.Index(t, ":[")
+		if i < 0 || !strings.HasSuffix(t, "]") {
+			f.Type = FDType(t)
+			return
+		}
+
+		f.Type = FDType(t[:i])
+		f.Inode, _ = strconv.ParseUint(t[i+2:len(t)-1], 10, 64)
+	}
+}
+
+// parseFDInfo parses the content of a /proc/<pid>/fdinfo/<fd> file named file into f.
+func (f *ProcFD) parseFDInfo(file string, dat []byte) error {
+	for n, line := range strings.Split(string(dat), "\n") {
+		// e.g. "flags:	0100002"
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+
+		key, val := line[:i], strings.TrimSpace(line[i+1:])
+
+		var err error
+		switch key {
+		case "pos":
+			f.Pos, err = strconv.ParseUint(val, 10, 64)
+		case "flags":
+			f.Flags, err = strconv.ParseUint(val, 8, 64)
+		case "mnt_id":
+			f.MntID, err = strconv.Atoi(val)
+		case "ino":
+			f.Ino, err = strconv.ParseUint(val, 10, 64)
+		}
+		if err != nil {
+			return &ParseError{File: file, Field: key, Line: n + 1, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// GetFileOpeners returns the file descriptors of all processes that have
+// the given file open, like fuser(1).
+func GetFileOpeners(target string) ([]ProcFD, error) {
+	return defaultFS.GetFileOpeners(target)
+}
+
+// GetFileOpeners returns the file descriptors of all processes that have
+// the given file open, like fuser(1). target is matched against the Target
+// and the Path of every file descriptor, so "socket:[12345]" and the path of
+// a deleted file both work. The result is in ascending PID and fd order.
+//
+// Processes that exit during the scan are skipped. If some other processes
+// cannot be read, e.g. with ErrPermission, the file descriptors found are
+// returned together with a *ScanError.
+func (pfs FS) GetFileOpeners(target string) ([]ProcFD, error) {
+	return pfs.GetFileOpenersContext(context.Background(), target, ScanOptions{})
+}
+
+// GetFileOpenersContext is like GetFileOpeners, with the scan configured by opts, but stops when ctx is done.
+func GetFileOpenersContext(ctx context.Context, target string, opts ScanOptions) ([]ProcFD, error) {
+	return defaultFS.GetFileOpenersContext(ctx, target, opts)
+}
+
+// GetFileOpenersContext is like GetFileOpeners, with the scan configured by opts, but stops when ctx is done.
+// In that case, the file descriptors found so far are returned together with ctx.Err().
+func (pfs FS) GetFileOpenersContext(ctx context.Context, target string, opts ScanOptions) ([]ProcFD, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pids, err := pfs.pids()
+	if err != nil {
+		return nil, err
+	}
+
+	fds_s := make([][]ProcFD, len(pids))
+
+	match := func(f *ProcFD) bool {
+		return f.Target == target || (f.Type == FDFile && f.Path == target)
+	}
+
+	read := func(i int) (interface{}, error) {
+		return pfs.processFDs(ctx, pids[i], match)
+	}
+
+	collect := func(i int, v interface{}) {
+		fds_s[i] = v.([]ProcFD)
+	}
+
+	errs, err := scan(ctx, len(pids), opts.Workers, read, collect)
+
+	var fds []ProcFD
+	for _, found := range fds_s {
+		fds = append(fds, found...)
+	}
+
+	if err != nil {
+		return fds, err
+	}
+
+	return fds, newScanError(pids, errs)
+}
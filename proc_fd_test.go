@@ -0,0 +1,180 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// linkFS is a fs.FS with symbolic links, given by their targets.
+type linkFS struct {
+	fs.FS
+	links map[string]string
+}
+
+func (l linkFS) ReadLink(name string) (string, error) {
+	target, ok := l.links[name]
+	if !ok {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return target, nil
+}
+
+// testFDFS is a procfs fixture with the file descriptors of two processes,
+// one of which closed fd 9 while it was listed, and a process of another
+// user (3).
+var testFDFS = linkFS{
+	FS: deniedFS{
+		FS: fstest.MapFS{
+			"1/fd/0":      {},
+			"1/fd/1":      {},
+			"1/fd/2":      {},
+			"1/fd/3":      {},
+			"1/fd/4":      {},
+			"1/fd/10":     {},
+			"1/fd/11":     {},
+			"1/fdinfo/0":  {Data: []byte("pos:\t0\nflags:\t0100000\nmnt_id:\t25\nino:\t5\n")},
+			"1/fdinfo/1":  {Data: []byte("pos:\t123456\nflags:\t02102001\nmnt_id:\t29\nino:\t131074\n")},
+			"1/fdinfo/2":  {Data: []byte("pos:\t0\nflags:\t02000002\nmnt_id:\t14\nino:\t40112\n")},
+			"1/fdinfo/3":  {Data: []byte("pos:\t0\nflags:\t02000000\nmnt_id:\t14\nino:\t40113\n")},
+			"1/fdinfo/4":  {Data: []byte("pos:\t0\nflags:\t02004002\nmnt_id:\t15\nino:\t1057\neventfd-count:\t0\neventfd-id: 3\n")},
+			"1/fdinfo/10": {Data: []byte("pos:\t4096\nflags:\t0100002\nmnt_id:\t29\n")},
+			"1/fdinfo/11": {Data: []byte("pos:\t0\nflags:\t02000000\nmnt_id:\t4\n")},
+			"2/fd/0":      {},
+			"2/fd/9":      {},
+			"2/fdinfo/0":  {Data: []byte("pos:\t10\nflags:\t0100001\nmnt_id:\t29\n")},
+			"3/fd/0":      {},
+		},
+		name: "3/fd",
+	},
+	links: map[string]string{
+		"1/fd/0":  "/dev/null",
+		"1/fd/1":  "/var/log/app.log",
+		"1/fd/2":  "socket:[40112]",
+		"1/fd/3":  "pipe:[40113]",
+		"1/fd/4":  "anon_inode:[eventfd]",
+		"1/fd/10": "/tmp/scratch (deleted)",
+		"1/fd/11": "net:[4026531840]",
+		"2/fd/0":  "/var/log/app.log",
+	},
+}
+
+// TestGetProcessFDs tests the classification of the fd targets and the parsing of fdinfo.
+func TestGetProcessFDs(t *testing.T) {
+	fds, err := NewFSFromFS(testFDFS).GetProcessFDs(1)
+	if err != nil {
+		t.Fatalf("GetProcessFDs(1): %v", err)
+	}
+
+	want := []ProcFD{
+		{Pid: 1, FD: 0, Target: "/dev/null", Type: FDFile, Path: "/dev/null", Flags: 0100000, MntID: 25, Ino: 5},
+		{Pid: 1, FD: 1, Target: "/var/log/app.log", Type: FDFile, Path: "/var/log/app.log", Pos: 123456, Flags: 02102001, MntID: 29, Ino: 131074},
+		{Pid: 1, FD: 2, Target: "socket:[40112]", Type: FDSocket, Inode: 40112, Flags: 02000002, MntID: 14, Ino: 40112},
+		{Pid: 1, FD: 3, Target: "pipe:[40113]", Type: FDPipe, Inode: 40113, Flags: 02000000, MntID: 14, Ino: 40113},
+		{Pid: 1, FD: 4, Target: "anon_inode:[eventfd]", Type: FDAnonInode, AnonType: "[eventfd]", Flags: 02004002, MntID: 15, Ino: 1057},
+		{Pid: 1, FD: 10, Target: "/tmp/scratch (deleted)", Type: FDFile, Path: "/tmp/scratch", Deleted: true, Pos: 4096, Flags: 0100002, MntID: 29},
+		{Pid: 1, FD: 11, Target: "net:[4026531840]", Type: "net", Inode: 4026531840, Flags: 02000000, MntID: 4},
+	}
+
+	if !reflect.DeepEqual(fds, want) {
+		t.Errorf("GetProcessFDs(1) = %+v; want %+v", fds, want)
+	}
+}
+
+// TestGetProcessFDsErrors tests closed file descriptors, denied processes,
+// canceled walks and file systems without symbolic links.
+func TestGetProcessFDsErrors(t *testing.T) {
+	fs := NewFSFromFS(testFDFS)
+
+	fds, err := fs.GetProcessFDs(2)
+	if err != nil || len(fds) != 1 || fds[0].FD != 0 {
+		t.Errorf("GetProcessFDs(2) = %+v, %v; want only fd 0", fds, err)
+	}
+
+	if _, err := fs.GetProcessFDs(3); !errors.Is(err, ErrPermission) {
+		t.Errorf("GetProcessFDs(3) = %v; want %v", err, ErrPermission)
+	}
+
+	if _, err := fs.GetProcessFDs(4); !errors.Is(err, ErrProcessGone) {
+		t.Errorf("GetProcessFDs(4) = %v; want %v", err, ErrProcessGone)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if fds, err := fs.GetProcessFDsContext(ctx, 1); fds != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("GetProcessFDsContext() with a canceled ctx = %+v, %v; want %v", fds, err, context.Canceled)
+	}
+
+	if _, err := NewFSFromFS(testFDFS.FS).GetProcessFDs(1); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("GetProcessFDs(1) without ReadLink = %v; want %v", err, ErrNotSupported)
+	}
+}
+
+// TestGetFileOpeners tests finding the processes that have a file open.
+func TestGetFileOpeners(t *testing.T) {
+	fs := NewFSFromFS(testFDFS)
+
+	fds, err := fs.GetFileOpenersContext(context.Background(), "/var/log/app.log", ScanOptions{Workers: 2})
+
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) || len(scanErr.Errors) != 1 || scanErr.Errors[0].Pid != 3 {
+		t.Errorf("GetFileOpenersContext() error = %v; want only PID 3", err)
+	}
+	if len(fds) != 2 || fds[0].Pid != 1 || fds[0].FD != 1 || fds[1].Pid != 2 || fds[1].FD != 0 {
+		t.Errorf("GetFileOpenersContext() = %+v; want PID 1 fd 1 and PID 2 fd 0", fds)
+	}
+
+	for _, target := range []string{"/tmp/scratch", "socket:[40112]"} {
+		fds, _ := fs.GetFileOpeners(target)
+		if len(fds) != 1 || fds[0].Pid != 1 {
+			t.Errorf("GetFileOpeners(%q) = %+v; want PID 1", target, fds)
+		}
+	}
+
+	// Only the fdinfo of the matches is read.
+	fs = NewFSFromFS(linkFS{FS: deniedFS{FS: testFDFS.FS, name: "1/fdinfo/2"}, links: testFDFS.links})
+	if fds, _ := fs.GetFileOpeners("/dev/null"); len(fds) != 1 || fds[0].MntID != 25 {
+		t.Errorf("GetFileOpeners(%q) with fdinfo/2 denied = %+v; want PID 1 fd 0", "/dev/null", fds)
+	}
+}
+
+// TestGetProcessFDsLive tests finding a file opened by the running process.
+func TestGetProcessFDsLive(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "open")
+
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString("hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	fds, err := GetProcessFDs(os.Getpid())
+	if err != nil {
+		t.Fatalf("GetProcessFDs(%v): %v", os.Getpid(), err)
+	}
+
+	var found bool
+	for _, fd := range fds {
+		if fd.FD == int(f.Fd()) {
+			found = fd.Type == FDFile && fd.Path == name && fd.Pos == 5 && fd.Flags&uint64(os.O_RDWR) != 0
+		}
+	}
+	if !found {
+		t.Errorf("GetProcessFDs(%v) = %+v; want fd %v open on %v", os.Getpid(), fds, f.Fd(), name)
+	}
+
+	openers, _ := GetFileOpeners(name)
+	if len(openers) != 1 || openers[0].Pid != os.Getpid() {
+		t.Errorf("GetFileOpeners(%q) = %+v; want PID %v", name, openers, os.Getpid())
+	}
+}
@@ -0,0 +1,137 @@
+package lpfs
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const procdir_per_process_io string = "io"
+
+// ProcIO contains process I/O accounting available in /proc/<pid>/io.
+//
+// Rchar and Wchar count the bytes passed to read and write system calls,
+// including those served from the page cache, while ReadBytes and
+// WriteBytes count the bytes fetched from and sent to the storage layer.
+type ProcIO struct {
+	Pid                 int
+	Rchar               uint64
+	Wchar               uint64
+	Syscr               uint64
+	Syscw               uint64
+	ReadBytes           uint64
+	WriteBytes          uint64
+	CancelledWriteBytes uint64
+}
+
+// GetProcessIO returns I/O accounting information of a giving process.
+func GetProcessIO(pid int) (ProcIO, error) {
+	return defaultFS.GetProcessIO(pid)
+}
+
+// GetProcessIO returns I/O accounting information of a giving process.
+// Only the owner of a process (or a privileged user) may read its I/O
+// accounting; other processes return an error matching ErrPermission.
+func (pfs FS) GetProcessIO(pid int) (ProcIO, error) {
+	ioFile := path.Join(strconv.Itoa(pid), procdir_per_process_io)
+
+	dat, err := pfs.readFile(ioFile)
+	if err != nil {
+		return ProcIO{}, err
+	}
+
+	return parseProcIO(ioFile, pid, dat)
+}
+
+// GetPerProcessIO returns I/O accounting information of all living processes in the system.
+func GetPerProcessIO() ([]ProcIO, error) {
+	return defaultFS.GetPerProcessIO()
+}
+
+// GetPerProcessIO returns I/O accounting information of all living processes in the system.
+// Processes that exit during the scan are skipped. If some other processes
+// cannot be read, e.g. with ErrPermission, the remaining ones are returned
+// together with a *ScanError.
+func (pfs FS) GetPerProcessIO() ([]ProcIO, error) {
+	return pfs.GetPerProcessIOContext(context.Background(), ScanOptions{})
+}
+
+// GetPerProcessIOContext is like GetPerProcessIO, with the scan configured by opts, but stops when ctx is done.
+func GetPerProcessIOContext(ctx context.Context, opts ScanOptions) ([]ProcIO, error) {
+	return defaultFS.GetPerProcessIOContext(ctx, opts)
+}
+
+// GetPerProcessIOContext is like GetPerProcessIO, with the scan configured by opts, but stops when ctx is done.
+// In that case, the processes read so far are returned together with ctx.Err().
+func (pfs FS) GetPerProcessIOContext(ctx context.Context, opts ScanOptions) ([]ProcIO, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pids, err := pfs.pids()
+	if err != nil {
+		return nil, err
+	}
+
+	pio_s := make([]ProcIO, len(pids))
+	found := make([]bool, len(pids))
+
+	read := func(i int) (interface{}, error) {
+		return pfs.GetProcessIO(pids[i])
+	}
+
+	collect := func(i int, v interface{}) {
+		pio_s[i] = v.(ProcIO)
+		found[i] = true
+	}
+
+	errs, err := scan(ctx, len(pids), opts.Workers, read, collect)
+
+	pio := make([]ProcIO, 0, len(pids))
+	for _, i := range foundIndices(found) {
+		pio = append(pio, pio_s[i])
+	}
+
+	if err != nil {
+		return pio, err
+	}
+
+	return pio, newScanError(pids, errs)
+}
+
+// parseProcIO parses the content of a /proc/<pid>/io file named file.
+func parseProcIO(file string, pid int, dat []byte) (ProcIO, error) {
+	p := ProcIO{Pid: pid}
+	fields := map[string]*uint64{
+		"rchar":                 &p.Rchar,
+		"wchar":                 &p.Wchar,
+		"syscr":                 &p.Syscr,
+		"syscw":                 &p.Syscw,
+		"read_bytes":            &p.ReadBytes,
+		"write_bytes":           &p.WriteBytes,
+		"cancelled_write_bytes": &p.CancelledWriteBytes,
+	}
+
+	for n, line := range strings.Split(string(dat), "\n") {
+		// e.g. "rchar: 3980"
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+
+		key := line[:i]
+		f, ok := fields[key]
+		if !ok {
+			continue
+		}
+
+		v, err := strconv.ParseUint(strings.TrimSpace(line[i+1:]), 10, 64)
+		if err != nil {
+			return ProcIO{}, &ParseError{File: file, Field: key, Line: n + 1, Err: err}
+		}
+		*f = v
+	}
+
+	return p, nil
+}
@@ -0,0 +1,86 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+// testIOFS is a procfs fixture with a process of another user (2), a
+// malformed process (100) and a process that has exited (200).
+var testIOFS = fstest.MapFS{
+	"1/io":       {Data: []byte("rchar: 323934931\nwchar: 323929600\nsyscr: 632687\nsyscw: 632675\nread_bytes: 0\nwrite_bytes: 323932160\ncancelled_write_bytes: 0\n")},
+	"2/io":       {Data: []byte("rchar: 1\n")},
+	"10/io":      {Data: []byte("rchar: 4096\nwchar: 0\nsyscr: 1\nsyscw: 0\n")},
+	"100/io":     {Data: []byte("rchar: -1\n")},
+	"200/status": {Data: []byte("Name:\tgone\n")},
+}
+
+// newTestIOFS returns a FS of testIOFS in which the I/O of process 2 cannot
+// be read and process 200 has exited.
+func newTestIOFS() FS {
+	return NewFSFromFS(deniedFS{FS: vanishedFS{FS: testIOFS, pid: "200"}, name: "2/io"})
+}
+
+// TestGetProcessIO tests parsing /proc/<pid>/io.
+func TestGetProcessIO(t *testing.T) {
+	fs := newTestIOFS()
+
+	p, err := fs.GetProcessIO(1)
+	want := ProcIO{Pid: 1, Rchar: 323934931, Wchar: 323929600, Syscr: 632687, Syscw: 632675, WriteBytes: 323932160}
+	if err != nil || p != want {
+		t.Errorf("GetProcessIO(1) = %+v, %v; want %+v", p, err, want)
+	}
+
+	// Without task I/O accounting, the storage fields are missing.
+	p, err = fs.GetProcessIO(10)
+	want = ProcIO{Pid: 10, Rchar: 4096, Syscr: 1}
+	if err != nil || p != want {
+		t.Errorf("GetProcessIO(10) = %+v, %v; want %+v", p, err, want)
+	}
+
+	if _, err := fs.GetProcessIO(2); !errors.Is(err, ErrPermission) {
+		t.Errorf("GetProcessIO(2) = %v; want %v", err, ErrPermission)
+	}
+
+	var perr *ParseError
+	if _, err := fs.GetProcessIO(100); !errors.As(err, &perr) || perr.Field != "rchar" {
+		t.Errorf("GetProcessIO(100) = %v; want ParseError for field rchar", err)
+	}
+}
+
+// TestGetPerProcessIO tests that the I/O scan skips exited processes and
+// reports the others that failed, including denied ones, in a ScanError.
+func TestGetPerProcessIO(t *testing.T) {
+	pio, err := newTestIOFS().GetPerProcessIOContext(context.Background(), ScanOptions{Workers: 4})
+
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) || len(scanErr.Errors) != 2 || scanErr.Errors[0].Pid != 2 || scanErr.Errors[1].Pid != 100 {
+		t.Errorf("GetPerProcessIOContext() error = %v; want PIDs 2 and 100", err)
+	}
+
+	if len(pio) != 2 || pio[0].Pid != 1 || pio[1].Pid != 10 {
+		t.Errorf("GetPerProcessIOContext() = %+v; want PIDs 1 and 10", pio)
+	}
+}
+
+// TestGetProcessIOLive tests reading the I/O accounting of the running process.
+func TestGetProcessIOLive(t *testing.T) {
+	p, err := GetProcessIO(os.Getpid())
+	if errors.Is(err, ErrNotSupported) {
+		t.Skip("kernel without task I/O accounting")
+	}
+	if err != nil {
+		t.Fatalf("GetProcessIO(%v): %v", os.Getpid(), err)
+	}
+
+	if p.Pid != os.Getpid() || p.Rchar == 0 || p.Syscr == 0 {
+		t.Errorf("GetProcessIO(%v) = %+v", os.Getpid(), p)
+	}
+
+	if q, err := GetProcessIO(os.Getpid()); err != nil || q.Rchar < p.Rchar {
+		t.Errorf("GetProcessIO(%v) = %+v, %v; want counters not below %+v", os.Getpid(), q, err, p)
+	}
+}
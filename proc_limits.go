@@ -0,0 +1,211 @@
+package lpfs
+
+import (
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const procdir_per_process_limits string = "limits"
+
+// Unlimited is the value of a Limit without bound (RLIM_INFINITY).
+const Unlimited uint64 = math.MaxUint64
+
+// Limit is a resource limit of a process, see getrlimit(2).
+type Limit struct {
+	Soft  uint64 // enforced value, or Unlimited
+	Hard  uint64 // ceiling for Soft, or Unlimited
+	Units string // e.g. "bytes" or "files", or "" for unitless limits
+}
+
+// Percent returns used in percent of the soft limit, or 0 if it is Unlimited.
+func (l Limit) Percent(used uint64) float64 {
+	switch {
+	case l.Soft == Unlimited:
+		return 0
+	case l.Soft == 0:
+		if used == 0 {
+			return 0
+		}
+		return 100
+	}
+
+	return float64(used) / float64(l.Soft) * 100
+}
+
+// ProcLimits contains process resource limits available in /proc/<pid>/limits.
+type ProcLimits struct {
+	CPUTime          Limit
+	FileSize         Limit
+	DataSize         Limit
+	StackSize        Limit
+	CoreFileSize     Limit
+	ResidentSet      Limit
+	Processes        Limit
+	OpenFiles        Limit
+	LockedMemory     Limit
+	AddressSpace     Limit
+	FileLocks        Limit
+	PendingSignals   Limit
+	MsgqueueSize     Limit
+	NicePriority     Limit
+	RealtimePriority Limit
+	RealtimeTimeout  Limit
+
+	// Other contains the limits not listed above, keyed by their name
+	// as in /proc/<pid>/limits.
+	Other map[string]Limit
+}
+
+// GetProcessLimits returns the resource limits of a giving process.
+func GetProcessLimits(pid int) (ProcLimits, error) {
+	return defaultFS.GetProcessLimits(pid)
+}
+
+// GetProcessLimits returns the resource limits of a giving process.
+func (pfs FS) GetProcessLimits(pid int) (ProcLimits, error) {
+	limitsFile := path.Join(strconv.Itoa(pid), procdir_per_process_limits)
+
+	dat, err := pfs.readFile(limitsFile)
+	if err != nil {
+		return ProcLimits{}, err
+	}
+
+	var l ProcLimits
+	fields := l.fields()
+
+	for n, line := range strings.Split(string(dat), "\n") {
+		// e.g. "Max open files            1024                 1048576              files     "
+		dat_s := strings.Fields(line)
+		if n == 0 || len(dat_s) == 0 {
+			continue
+		}
+
+		// The name has a variable number of words, and is followed by the
+		// first value.
+		i := 0
+		for i < len(dat_s) && !isLimitValue(dat_s[i]) {
+			i++
+		}
+		if i == 0 || i+2 > len(dat_s) {
+			return ProcLimits{}, &ParseError{File: limitsFile, Line: n + 1, Err: errUnexpectedFormat(line)}
+		}
+
+		name := strings.Join(dat_s[:i], " ")
+		limit := Limit{Soft: parseLimitValue(dat_s[i]), Hard: parseLimitValue(dat_s[i+1])}
+		if i+2 < len(dat_s) {
+			limit.Units = dat_s[i+2]
+		}
+
+		if f, ok := fields[name]; ok {
+			*f = limit
+			continue
+		}
+
+		if l.Other == nil {
+			l.Other = make(map[string]Limit)
+		}
+		l.Other[name] = limit
+	}
+
+	return l, nil
+}
+
+// fields maps the /proc/<pid>/limits names to the ProcLimits fields.
+func (l *ProcLimits) fields() map[string]*Limit {
+	return map[string]*Limit{
+		"Max cpu time":          &l.CPUTime,
+		"Max file size":         &l.FileSize,
+		"Max data size":         &l.DataSize,
+		"Max stack size":        &l.StackSize,
+		"Max core file size":    &l.CoreFileSize,
+		"Max resident set":      &l.ResidentSet,
+		"Max processes":         &l.Processes,
+		"Max open files":        &l.OpenFiles,
+		"Max locked memory":     &l.LockedMemory,
+		"Max address space":     &l.AddressSpace,
+		"Max file locks":        &l.FileLocks,
+		"Max pending signals":   &l.PendingSignals,
+		"Max msgqueue size":     &l.MsgqueueSize,
+		"Max nice priority":     &l.NicePriority,
+		"Max realtime priority": &l.RealtimePriority,
+		"Max realtime timeout":  &l.RealtimeTimeout,
+	}
+}
+
+// isLimitValue reports whether s is a limit value, i.e. a number or "unlimited".
+func isLimitValue(s string) bool {
+	return s == "unlimited" || isNumeric(s)
+}
+
+// parseLimitValue parses a limit value checked by isLimitValue.
+func parseLimitValue(s string) uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return Unlimited
+	}
+
+	return v
+}
+
+// LimitUsage compares the current usage of a resource with its limit.
+type LimitUsage struct {
+	Limit
+	Used    uint64  // current usage, in the Units of Limit
+	Percent float64 // Used in percent of Limit.Soft, or 0 if it is Unlimited
+}
+
+// ProcLimitUsage contains the usage of the resource limits that processes
+// most often run into.
+type ProcLimitUsage struct {
+	OpenFiles    LimitUsage // open file descriptors
+	Processes    LimitUsage // threads of the process, see Usage
+	AddressSpace LimitUsage // virtual memory size, in bytes
+}
+
+// Usage compares p, the stat of the process, and openFDs, its number of open
+// file descriptors, with the limits.
+//
+// The Processes limit applies to all the processes of the real user ID of
+// the process, but only the threads of the process itself are counted, so
+// Processes.Used is a lower bound.
+func (l ProcLimits) Usage(p Procstat, openFDs int) ProcLimitUsage {
+	usage := func(limit Limit, used uint64) LimitUsage {
+		return LimitUsage{Limit: limit, Used: used, Percent: limit.Percent(used)}
+	}
+
+	return ProcLimitUsage{
+		OpenFiles:    usage(l.OpenFiles, uint64(openFDs)),
+		Processes:    usage(l.Processes, p.NumThreads),
+		AddressSpace: usage(l.AddressSpace, p.Vsize),
+	}
+}
+
+// GetProcessLimitUsage returns the usage of the resource limits of a giving process.
+func GetProcessLimitUsage(pid int) (ProcLimitUsage, error) {
+	return defaultFS.GetProcessLimitUsage(pid)
+}
+
+// GetProcessLimitUsage returns the usage of the resource limits of a giving process.
+// It reads /proc/<pid>/limits, /proc/<pid>/stat and /proc/<pid>/fd, which
+// only the owner of a process (or a privileged user) may list; other
+// processes return an error matching ErrPermission.
+func (pfs FS) GetProcessLimitUsage(pid int) (ProcLimitUsage, error) {
+	l, err := pfs.GetProcessLimits(pid)
+	if err != nil {
+		return ProcLimitUsage{}, err
+	}
+
+	p, err := pfs.GetProcessStat(pid)
+	if err != nil {
+		return ProcLimitUsage{}, err
+	}
+
+	fds, err := pfs.readDir(strconv.Itoa(pid), procdir_per_process_fd)
+	if err != nil {
+		return ProcLimitUsage{}, err
+	}
+
+	return l.Usage(p, len(fds)), nil
+}
@@ -0,0 +1,121 @@
+package lpfs
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+const testLimits = `Limit                     Soft Limit           Hard Limit           Units     
+Max cpu time              unlimited            unlimited            seconds   
+Max file size             unlimited            unlimited            bytes     
+Max data size             unlimited            unlimited            bytes     
+Max stack size            8388608              unlimited            bytes     
+Max core file size        0                    unlimited            bytes     
+Max resident set          unlimited            unlimited            bytes     
+Max processes             63448                63448                processes 
+Max open files            1024                 524288               files     
+Max locked memory         8388608              8388608              bytes     
+Max address space         4294967296           unlimited            bytes     
+Max file locks            unlimited            unlimited            locks     
+Max pending signals       63448                63448                signals   
+Max msgqueue size         819200               819200               bytes     
+Max nice priority         0                    0                    
+Max realtime priority     0                    0                    
+Max realtime timeout      unlimited            unlimited            us        
+Max future thing          10                   20                   widgets   
+`
+
+// TestGetProcessLimits tests parsing /proc/<pid>/limits.
+func TestGetProcessLimits(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"1/limits": {Data: []byte(testLimits)},
+		"2/limits": {Data: []byte("Limit                     Soft Limit           Hard Limit           Units\nMax open files lots\n")},
+	})
+
+	l, err := fs.GetProcessLimits(1)
+	if err != nil {
+		t.Fatalf("GetProcessLimits(1): %v", err)
+	}
+
+	want := ProcLimits{
+		CPUTime:          Limit{Soft: Unlimited, Hard: Unlimited, Units: "seconds"},
+		FileSize:         Limit{Soft: Unlimited, Hard: Unlimited, Units: "bytes"},
+		DataSize:         Limit{Soft: Unlimited, Hard: Unlimited, Units: "bytes"},
+		StackSize:        Limit{Soft: 8388608, Hard: Unlimited, Units: "bytes"},
+		CoreFileSize:     Limit{Soft: 0, Hard: Unlimited, Units: "bytes"},
+		ResidentSet:      Limit{Soft: Unlimited, Hard: Unlimited, Units: "bytes"},
+		Processes:        Limit{Soft: 63448, Hard: 63448, Units: "processes"},
+		OpenFiles:        Limit{Soft: 1024, Hard: 524288, Units: "files"},
+		LockedMemory:     Limit{Soft: 8388608, Hard: 8388608, Units: "bytes"},
+		AddressSpace:     Limit{Soft: 4294967296, Hard: Unlimited, Units: "bytes"},
+		FileLocks:        Limit{Soft: Unlimited, Hard: Unlimited, Units: "locks"},
+		PendingSignals:   Limit{Soft: 63448, Hard: 63448, Units: "signals"},
+		MsgqueueSize:     Limit{Soft: 819200, Hard: 819200, Units: "bytes"},
+		NicePriority:     Limit{},
+		RealtimePriority: Limit{},
+		RealtimeTimeout:  Limit{Soft: Unlimited, Hard: Unlimited, Units: "us"},
+		Other:            map[string]Limit{"Max future thing": {Soft: 10, Hard: 20, Units: "widgets"}},
+	}
+	if !reflect.DeepEqual(l, want) {
+		t.Errorf("GetProcessLimits(1) = %+v; want %+v", l, want)
+	}
+
+	var perr *ParseError
+	if _, err := fs.GetProcessLimits(2); !errors.As(err, &perr) || perr.Line != 2 {
+		t.Errorf("GetProcessLimits(2) = %v; want ParseError for line 2", err)
+	}
+}
+
+// TestGetProcessLimitUsage tests comparing the usage of a process with its limits.
+func TestGetProcessLimitUsage(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"1/limits": {Data: []byte(testLimits)},
+		"1/stat":   {Data: []byte("1 (java) S 0 1 1 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 634 0 27 3221225472 3199 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+		"1/fd/0":   {},
+		"1/fd/1":   {},
+		"1/fd/2":   {},
+		"1/fd/3":   {},
+	})
+
+	u, err := fs.GetProcessLimitUsage(1)
+	threads, maxProcs := 634.0, 63448.0
+	want := ProcLimitUsage{
+		OpenFiles:    LimitUsage{Limit: Limit{Soft: 1024, Hard: 524288, Units: "files"}, Used: 4, Percent: 0.390625},
+		Processes:    LimitUsage{Limit: Limit{Soft: 63448, Hard: 63448, Units: "processes"}, Used: 634, Percent: threads / maxProcs * 100},
+		AddressSpace: LimitUsage{Limit: Limit{Soft: 4294967296, Hard: Unlimited, Units: "bytes"}, Used: 3221225472, Percent: 75},
+	}
+	if err != nil || u != want {
+		t.Errorf("GetProcessLimitUsage(1) = %+v, %v; want %+v", u, err, want)
+	}
+
+	tests := []struct {
+		limit Limit
+		used  uint64
+		want  float64
+	}{
+		{Limit{Soft: Unlimited, Hard: Unlimited}, 1 << 40, 0},
+		{Limit{Soft: 0, Hard: Unlimited}, 0, 0},
+		{Limit{Soft: 0, Hard: Unlimited}, 1, 100},
+		{Limit{Soft: 200, Hard: 400}, 300, 150},
+	}
+	for _, tt := range tests {
+		if p := tt.limit.Percent(tt.used); p != tt.want {
+			t.Errorf("%+v.Percent(%v) = %v; want %v", tt.limit, tt.used, p, tt.want)
+		}
+	}
+}
+
+// TestGetProcessLimitsLive tests reading the limits of the running process.
+func TestGetProcessLimitsLive(t *testing.T) {
+	u, err := GetProcessLimitUsage(os.Getpid())
+	if err != nil {
+		t.Fatalf("GetProcessLimitUsage(%v): %v", os.Getpid(), err)
+	}
+
+	if u.OpenFiles.Used < 3 || u.OpenFiles.Units != "files" || u.Processes.Used < 1 || u.AddressSpace.Used == 0 {
+		t.Errorf("GetProcessLimitUsage(%v) = %+v", os.Getpid(), u)
+	}
+}
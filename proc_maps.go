@@ -0,0 +1,396 @@
+package lpfs
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	procdir_per_process_maps         string = "maps"
+	procdir_per_process_smaps        string = "smaps"
+	procdir_per_process_smaps_rollup string = "smaps_rollup"
+)
+
+// ProcMap contains a memory mapping of a process, available in /proc/<pid>/maps.
+type ProcMap struct {
+	Start    uint64 // start address
+	End      uint64 // end address, exclusive
+	Perms    string // e.g. "r-xp"
+	Offset   uint64 // offset in the mapped file
+	DevMajor uint32
+	DevMinor uint32
+	Inode    uint64
+
+	// Path is the mapped file, a pseudo-path such as "[heap]" or "[stack]",
+	// or "" for anonymous mappings. Deleted files end in " (deleted)".
+	Path string
+}
+
+// SmapsStats contains the memory usage of a mapping, or of a whole process,
+// available in /proc/<pid>/smaps and /proc/<pid>/smaps_rollup.
+// Sizes are in bytes.
+type SmapsStats struct {
+	Size           uint64
+	KernelPageSize uint64
+	MMUPageSize    uint64
+	Rss            uint64
+	Pss            uint64
+	PssDirty       uint64
+	PssAnon        uint64
+	PssFile        uint64
+	PssShmem       uint64
+	SharedClean    uint64
+	SharedDirty    uint64
+	PrivateClean   uint64
+	PrivateDirty   uint64
+	Referenced     uint64
+	Anonymous      uint64
+	KSM            uint64
+	LazyFree       uint64
+	AnonHugePages  uint64
+	ShmemPmdMapped uint64
+	FilePmdMapped  uint64
+	SharedHugetlb  uint64
+	PrivateHugetlb uint64
+	Swap           uint64
+	SwapPss        uint64
+	Locked         uint64
+
+	// Other contains the fields not listed above, keyed by their name in
+	// smaps. Sizes are in bytes, other values (e.g. "THPeligible") as is.
+	Other map[string]uint64
+}
+
+// USS returns the unique set size, the memory that would be freed if the
+// process exited: its private clean and dirty pages.
+func (s SmapsStats) USS() uint64 {
+	return s.PrivateClean + s.PrivateDirty
+}
+
+// ProcSmap contains a memory mapping of a process and its memory usage,
+// available in /proc/<pid>/smaps.
+type ProcSmap struct {
+	ProcMap
+	SmapsStats
+
+	VmFlags []string // e.g. "rd", "mr", "mw"
+}
+
+// GetProcessMaps returns the memory mappings of a giving process, in address order.
+func GetProcessMaps(pid int) ([]ProcMap, error) {
+	return defaultFS.GetProcessMaps(pid)
+}
+
+// GetProcessMaps returns the memory mappings of a giving process, in address order.
+func (pfs FS) GetProcessMaps(pid int) ([]ProcMap, error) {
+	mapsFile := path.Join(strconv.Itoa(pid), procdir_per_process_maps)
+
+	dat, err := pfs.readFile(mapsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var maps []ProcMap
+
+	for n, line := range strings.Split(string(dat), "\n") {
+		if line == "" {
+			continue
+		}
+
+		m, err := parseProcMap(line)
+		if err != nil {
+			return nil, &ParseError{File: mapsFile, Line: n + 1, Err: err}
+		}
+
+		maps = append(maps, m)
+	}
+
+	return maps, nil
+}
+
+// GetProcessSmaps returns the memory mappings of a giving process and their memory usage.
+func GetProcessSmaps(pid int) ([]ProcSmap, error) {
+	return defaultFS.GetProcessSmaps(pid)
+}
+
+// GetProcessSmaps returns the memory mappings of a giving process and their memory usage.
+// Only the owner of a process (or a privileged user) may read its smaps;
+// other processes return an error matching ErrPermission.
+func (pfs FS) GetProcessSmaps(pid int) ([]ProcSmap, error) {
+	smapsFile := path.Join(strconv.Itoa(pid), procdir_per_process_smaps)
+
+	dat, err := pfs.readFile(smapsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var smaps []ProcSmap
+
+	for n, line := range strings.Split(string(dat), "\n") {
+		dat_s := strings.Fields(line)
+		if len(dat_s) == 0 {
+			continue
+		}
+
+		// Mappings start with a line as in maps, followed by their fields,
+		// e.g. "Rss:                   8 kB".
+		key := dat_s[0]
+		if !strings.HasSuffix(key, ":") {
+			m, err := parseProcMap(line)
+			if err != nil {
+				return nil, &ParseError{File: smapsFile, Line: n + 1, Err: err}
+			}
+
+			smaps = append(smaps, ProcSmap{ProcMap: m})
+			continue
+		}
+
+		if len(smaps) == 0 {
+			return nil, &ParseError{File: smapsFile, Line: n + 1, Err: errUnexpectedFormat(line)}
+		}
+		s := &smaps[len(smaps)-1]
+
+		if key == "VmFlags:" {
+			s.VmFlags = dat_s[1:]
+			continue
+		}
+
+		if err := s.SmapsStats.set(line); err != nil {
+			return nil, &ParseError{File: smapsFile, Field: strings.TrimSuffix(key, ":"), Line: n + 1, Err: err}
+		}
+	}
+
+	return smaps, nil
+}
+
+// GetProcessSmapsRollup returns the memory usage of a giving process, summed over its mappings.
+func GetProcessSmapsRollup(pid int) (SmapsStats, error) {
+	return defaultFS.GetProcessSmapsRollup(pid)
+}
+
+// GetProcessSmapsRollup returns the memory usage of a giving process, summed over its mappings.
+// Its Pss is the proportional set size of the process and its USS() the unique set size.
+// On kernels without /proc/<pid>/smaps_rollup (before Linux 4.14), it is
+// computed from /proc/<pid>/smaps.
+func (pfs FS) GetProcessSmapsRollup(pid int) (SmapsStats, error) {
+	rollupFile := path.Join(strconv.Itoa(pid), procdir_per_process_smaps_rollup)
+
+	dat, err := pfs.readFile(rollupFile)
+	if errors.Is(err, ErrNotSupported) {
+		smaps, err := pfs.GetProcessSmaps(pid)
+		if err != nil {
+			return SmapsStats{}, err
+		}
+
+		return SumSmaps(smaps), nil
+	}
+	if err != nil {
+		return SmapsStats{}, err
+	}
+
+	var s SmapsStats
+
+	// The first line is a pseudo mapping spanning all the others, e.g.
+	// "562697607000-7ffca3950000 ---p 00000000 00:00 0    [rollup]".
+	for n, line := range strings.Split(string(dat), "\n") {
+		if n == 0 || line == "" {
+			continue
+		}
+
+		if err := s.set(line); err != nil {
+			key := strings.SplitN(line, ":", 2)[0]
+			return SmapsStats{}, &ParseError{File: rollupFile, Field: key, Line: n + 1, Err: err}
+		}
+	}
+
+	return s, nil
+}
+
+// SumSmaps returns the memory usage of smaps summed over the mappings.
+// The page sizes and the Other fields, which cannot be summed, are left zero.
+func SumSmaps(smaps []ProcSmap) SmapsStats {
+	var total SmapsStats
+
+	for i := range smaps {
+		total.add(&smaps[i].SmapsStats)
+	}
+
+	return total
+}
+
+// parseProcMap parses a line of /proc/<pid>/maps, e.g.
+// "562d5025d000-562d50263000 r-xp 00002000 fe:00 681885    /usr/bin/head".
+func parseProcMap(line string) (ProcMap, error) {
+	var m ProcMap
+
+	// The path may contain spaces, so split off the first five fields only.
+	var dat_s [5]string
+	rest := line
+	for i := range dat_s {
+		rest = strings.TrimLeft(rest, " ")
+		j := strings.IndexByte(rest, ' ')
+		if j < 0 {
+			j = len(rest)
+		}
+		dat_s[i], rest = rest[:j], rest[j:]
+	}
+	m.Path = strings.TrimLeft(rest, " ")
+
+	addr := strings.SplitN(dat_s[0], "-", 2)
+	dev := strings.SplitN(dat_s[3], ":", 2)
+	if len(addr) != 2 || len(dev) != 2 || dat_s[4] == "" {
+		return ProcMap{}, errUnexpectedFormat(line)
+	}
+
+	var err error
+	if m.Start, err = strconv.ParseUint(addr[0], 16, 64); err != nil {
+		return ProcMap{}, err
+	}
+	if m.End, err = strconv.ParseUint(addr[1], 16, 64); err != nil {
+		return ProcMap{}, err
+	}
+
+	m.Perms = dat_s[1]
+
+	if m.Offset, err = strconv.ParseUint(dat_s[2], 16, 64); err != nil {
+		return ProcMap{}, err
+	}
+
+	major, err := strconv.ParseUint(dev[0], 16, 32)
+	if err != nil {
+		return ProcMap{}, err
+	}
+	minor, err := strconv.ParseUint(dev[1], 16, 32)
+	if err != nil {
+		return ProcMap{}, err
+	}
+	m.DevMajor, m.DevMinor = uint32(major), uint32(minor)
+
+	if m.Inode, err = strconv.ParseUint(dat_s[4], 10, 64); err != nil {
+		return ProcMap{}, err
+	}
+
+	return m, nil
+}
+
+// set parses a smaps field line, e.g. "Pss_Dirty:           100 kB", into s.
+func (s *SmapsStats) set(line string) error {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return errUnexpectedFormat(line)
+	}
+
+	key := line[:i]
+	dat_s := strings.Fields(line[i+1:])
+	if len(dat_s) == 0 {
+		return errUnexpectedFormat(line)
+	}
+
+	v, err := strconv.ParseUint(dat_s[0], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if len(dat_s) > 1 && dat_s[1] == "kB" {
+		v *= 1024
+	}
+
+	if f := s.field(key); f != nil {
+		*f = v
+		return nil
+	}
+
+	if s.Other == nil {
+		s.Other = make(map[string]uint64)
+	}
+	s.Other[key] = v
+
+	return nil
+}
+
+// field returns the SmapsStats field of the smaps key, or nil for the Other fields.
+func (s *SmapsStats) field(key string) *uint64 {
+	switch key {
+	case "Size":
+		return &s.Size
+	case "KernelPageSize":
+		return &s.KernelPageSize
+	case "MMUPageSize":
+		return &s.MMUPageSize
+	case "Rss":
+		return &s.Rss
+	case "Pss":
+		return &s.Pss
+	case "Pss_Dirty":
+		return &s.PssDirty
+	case "Pss_Anon":
+		return &s.PssAnon
+	case "Pss_File":
+		return &s.PssFile
+	case "Pss_Shmem":
+		return &s.PssShmem
+	case "Shared_Clean":
+		return &s.SharedClean
+	case "Shared_Dirty":
+		return &s.SharedDirty
+	case "Private_Clean":
+		return &s.PrivateClean
+	case "Private_Dirty":
+		return &s.PrivateDirty
+	case "Referenced":
+		return &s.Referenced
+	case "Anonymous":
+		return &s.Anonymous
+	case "KSM":
+		return &s.KSM
+	case "LazyFree":
+		return &s.LazyFree
+	case "AnonHugePages":
+		return &s.AnonHugePages
+	case "ShmemPmdMapped":
+		return &s.ShmemPmdMapped
+	case "FilePmdMapped":
+		return &s.FilePmdMapped
+	case "Shared_Hugetlb":
+		return &s.SharedHugetlb
+	case "Private_Hugetlb":
+		return &s.PrivateHugetlb
+	case "Swap":
+		return &s.Swap
+	case "SwapPss":
+		return &s.SwapPss
+	case "Locked":
+		return &s.Locked
+	}
+
+	return nil
+}
+
+// add adds the fields of o to s, except for the page sizes and the Other fields.
+func (s *SmapsStats) add(o *SmapsStats) {
+	s.Size += o.Size
+	s.Rss += o.Rss
+	s.Pss += o.Pss
+	s.PssDirty += o.PssDirty
+	s.PssAnon += o.PssAnon
+	s.PssFile += o.PssFile
+	s.PssShmem += o.PssShmem
+	s.SharedClean += o.SharedClean
+	s.SharedDirty += o.SharedDirty
+	s.PrivateClean += o.PrivateClean
+	s.PrivateDirty += o.PrivateDirty
+	s.Referenced += o.Referenced
+	s.Anonymous += o.Anonymous
+	s.KSM += o.KSM
+	s.LazyFree += o.LazyFree
+	s.AnonHugePages += o.AnonHugePages
+	s.ShmemPmdMapped += o.ShmemPmdMapped
+	s.FilePmdMapped += o.FilePmdMapped
+	s.SharedHugetlb += o.SharedHugetlb
+	s.PrivateHugetlb += o.PrivateHugetlb
+	s.Swap += o.Swap
+	s.SwapPss += o.SwapPss
+	s.Locked += o.Locked
+}
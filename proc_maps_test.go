@@ -0,0 +1,173 @@
+package lpfs
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testMaps = `562d5025b000-562d5025d000 r--p 00000000 fe:00 681885                     /usr/bin/head
+562d5025d000-562d50263000 r-xp 00002000 fe:00 681885                     /usr/bin/head
+562d51a2b000-562d51a4c000 rw-p 00000000 00:00 0                          [heap]
+7f1c2a000000-7f1c2a021000 rw-p 00000000 00:00 0
+7f1c2b000000-7f1c2b400000 rw-s 00000000 00:05 1234                       /memfd:my pool (deleted)
+`
+
+const testSmaps = `562d5025b000-562d5025d000 r--p 00000000 fe:00 681885                     /usr/bin/head
+Size:                  8 kB
+KernelPageSize:        4 kB
+MMUPageSize:           4 kB
+Rss:                   8 kB
+Pss:                   4 kB
+Shared_Clean:          8 kB
+Shared_Dirty:          0 kB
+Private_Clean:         0 kB
+Private_Dirty:         0 kB
+Swap:                  0 kB
+SwapPss:               0 kB
+THPeligible:           0
+VmFlags: rd mr mw me
+562d51a2b000-562d51a4c000 rw-p 00000000 00:00 0                          [heap]
+Size:                132 kB
+KernelPageSize:        4 kB
+MMUPageSize:           4 kB
+Rss:                 100 kB
+Pss:                 100 kB
+Shared_Clean:          0 kB
+Shared_Dirty:          0 kB
+Private_Clean:        12 kB
+Private_Dirty:        88 kB
+Anonymous:           100 kB
+AnonHugePages:         0 kB
+Swap:                 16 kB
+SwapPss:              16 kB
+THPeligible:           1
+VmFlags: rd wr mr mw me ac
+`
+
+// TestGetProcessMaps tests parsing /proc/<pid>/maps.
+func TestGetProcessMaps(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"1/maps": {Data: []byte(testMaps)},
+		"2/maps": {Data: []byte("562d5025b000 r--p 00000000 fe:00 681885\n")},
+	})
+
+	maps, err := fs.GetProcessMaps(1)
+	want := []ProcMap{
+		{Start: 0x562d5025b000, End: 0x562d5025d000, Perms: "r--p", DevMajor: 0xfe, Inode: 681885, Path: "/usr/bin/head"},
+		{Start: 0x562d5025d000, End: 0x562d50263000, Perms: "r-xp", Offset: 0x2000, DevMajor: 0xfe, Inode: 681885, Path: "/usr/bin/head"},
+		{Start: 0x562d51a2b000, End: 0x562d51a4c000, Perms: "rw-p", Path: "[heap]"},
+		{Start: 0x7f1c2a000000, End: 0x7f1c2a021000, Perms: "rw-p"},
+		{Start: 0x7f1c2b000000, End: 0x7f1c2b400000, Perms: "rw-s", DevMinor: 5, Inode: 1234, Path: "/memfd:my pool (deleted)"},
+	}
+	if err != nil || !reflect.DeepEqual(maps, want) {
+		t.Errorf("GetProcessMaps(1) = %+v, %v; want %+v", maps, err, want)
+	}
+
+	var perr *ParseError
+	if _, err := fs.GetProcessMaps(2); !errors.As(err, &perr) || perr.File != "2/maps" || perr.Line != 1 {
+		t.Errorf("GetProcessMaps(2) = %v; want ParseError for 2/maps line 1", err)
+	}
+}
+
+// TestGetProcessSmaps tests parsing /proc/<pid>/smaps and summing it into
+// the process totals when smaps_rollup is missing.
+func TestGetProcessSmaps(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"1/smaps": {Data: []byte(testSmaps)},
+		"2/smaps": {Data: []byte(testSmaps + "  \n")},
+	})
+
+	smaps, err := fs.GetProcessSmaps(1)
+	if err != nil || len(smaps) != 2 {
+		t.Fatalf("GetProcessSmaps(1) = %+v, %v; want 2 mappings", smaps, err)
+	}
+
+	want := ProcSmap{
+		ProcMap: ProcMap{Start: 0x562d51a2b000, End: 0x562d51a4c000, Perms: "rw-p", Path: "[heap]"},
+		SmapsStats: SmapsStats{
+			Size: 132 << 10, KernelPageSize: 4 << 10, MMUPageSize: 4 << 10,
+			Rss: 100 << 10, Pss: 100 << 10, PrivateClean: 12 << 10, PrivateDirty: 88 << 10,
+			Anonymous: 100 << 10, Swap: 16 << 10, SwapPss: 16 << 10,
+			Other: map[string]uint64{"THPeligible": 1},
+		},
+		VmFlags: []string{"rd", "wr", "mr", "mw", "me", "ac"},
+	}
+	if !reflect.DeepEqual(smaps[1], want) {
+		t.Errorf("GetProcessSmaps(1)[1] = %+v; want %+v", smaps[1], want)
+	}
+
+	if smaps2, err := fs.GetProcessSmaps(2); err != nil || !reflect.DeepEqual(smaps2, smaps) {
+		t.Errorf("GetProcessSmaps(2) with a blank line = %+v, %v; want %+v", smaps2, err, smaps)
+	}
+
+	total, err := fs.GetProcessSmapsRollup(1)
+	wantTotal := SmapsStats{
+		Size: 140 << 10, Rss: 108 << 10, Pss: 104 << 10, SharedClean: 8 << 10,
+		PrivateClean: 12 << 10, PrivateDirty: 88 << 10, Anonymous: 100 << 10,
+		Swap: 16 << 10, SwapPss: 16 << 10,
+	}
+	if err != nil || !reflect.DeepEqual(total, wantTotal) {
+		t.Errorf("GetProcessSmapsRollup(1) without smaps_rollup = %+v, %v; want %+v", total, err, wantTotal)
+	}
+	if total.USS() != 100<<10 {
+		t.Errorf("USS() = %v; want %v", total.USS(), 100<<10)
+	}
+}
+
+// BenchmarkGetProcessSmaps measures parsing the smaps of a process with
+// thousands of mappings.
+func BenchmarkGetProcessSmaps(b *testing.B) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"1/smaps": {Data: []byte(strings.Repeat(testSmaps, 2500))},
+	})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := fs.GetProcessSmapsRollup(1); err != nil {
+			b.Fatalf("%v", err)
+		}
+	}
+}
+
+// TestGetProcessSmapsRollup tests parsing /proc/<pid>/smaps_rollup.
+func TestGetProcessSmapsRollup(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"1/smaps_rollup": {Data: []byte("562697607000-7ffca3950000 ---p 00000000 00:00 0                          [rollup]\n" +
+			"Rss:                1408 kB\nPss:                 361 kB\nPss_Dirty:           100 kB\n" +
+			"Shared_Clean:       1268 kB\nPrivate_Clean:        40 kB\nPrivate_Dirty:       100 kB\n")},
+		"2/smaps_rollup": {Data: []byte("562697607000-7ffca3950000 ---p 00000000 00:00 0 [rollup]\nRss: many kB\n")},
+	})
+
+	s, err := fs.GetProcessSmapsRollup(1)
+	want := SmapsStats{Rss: 1408 << 10, Pss: 361 << 10, PssDirty: 100 << 10, SharedClean: 1268 << 10, PrivateClean: 40 << 10, PrivateDirty: 100 << 10}
+	if err != nil || !reflect.DeepEqual(s, want) || s.USS() != 140<<10 {
+		t.Errorf("GetProcessSmapsRollup(1) = %+v, %v; want %+v", s, err, want)
+	}
+
+	var perr *ParseError
+	if _, err := fs.GetProcessSmapsRollup(2); !errors.As(err, &perr) || perr.Field != "Rss" || perr.Line != 2 {
+		t.Errorf("GetProcessSmapsRollup(2) = %v; want ParseError for line 2 field Rss", err)
+	}
+}
+
+// TestGetProcessSmapsLive tests reading the maps, smaps and smaps_rollup of the running process.
+func TestGetProcessSmapsLive(t *testing.T) {
+	maps, err := GetProcessMaps(os.Getpid())
+	if err != nil || len(maps) == 0 {
+		t.Fatalf("GetProcessMaps(%v) = %v, %v", os.Getpid(), maps, err)
+	}
+
+	smaps, err := GetProcessSmaps(os.Getpid())
+	if err != nil || len(smaps) == 0 {
+		t.Fatalf("GetProcessSmaps(%v) = %v, %v", os.Getpid(), len(smaps), err)
+	}
+
+	rollup, err := GetProcessSmapsRollup(os.Getpid())
+	if err != nil || rollup.Rss == 0 || rollup.Pss == 0 || rollup.USS() > rollup.Rss {
+		t.Errorf("GetProcessSmapsRollup(%v) = %+v, %v", os.Getpid(), rollup, err)
+	}
+}
@@ -0,0 +1,192 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const procdir_per_process_ns string = "ns"
+
+// ProcNamespaces contains the namespaces of a process, available in
+// /proc/<pid>/ns, identified by their inode numbers. Two processes are in
+// the same namespace if they have the same inode. Namespaces not supported
+// by the kernel are 0.
+type ProcNamespaces struct {
+	Pid             int
+	Cgroup          uint64
+	IPC             uint64
+	Mnt             uint64
+	Net             uint64
+	PID             uint64
+	PIDForChildren  uint64 // PID namespace of the children of the process
+	Time            uint64
+	TimeForChildren uint64 // time namespace of the children of the process
+	User            uint64
+	UTS             uint64
+}
+
+// fields maps the /proc/<pid>/ns link names to the ProcNamespaces fields.
+func (n *ProcNamespaces) fields() map[string]*uint64 {
+	return map[string]*uint64{
+		"cgroup":            &n.Cgroup,
+		"ipc":               &n.IPC,
+		"mnt":               &n.Mnt,
+		"net":               &n.Net,
+		"pid":               &n.PID,
+		"pid_for_children":  &n.PIDForChildren,
+		"time":              &n.Time,
+		"time_for_children": &n.TimeForChildren,
+		"user":              &n.User,
+		"uts":               &n.UTS,
+	}
+}
+
+// GetProcessNamespaces returns the namespaces of a giving process.
+func GetProcessNamespaces(pid int) (ProcNamespaces, error) {
+	return defaultFS.GetProcessNamespaces(pid)
+}
+
+// GetProcessNamespaces returns the namespaces of a giving process.
+// Namespaces whose link cannot be followed, e.g. most of those of a zombie,
+// are left 0.
+// Only the owner of a process (or a privileged user) may read its
+// namespaces; other processes return an error matching ErrPermission.
+//
+// The namespaces can only be read if the FS was created by NewFS, or if its
+// fs.FS has a ReadLink method; otherwise an error matching ErrNotSupported
+// is returned.
+func (pfs FS) GetProcessNamespaces(pid int) (ProcNamespaces, error) {
+	p := strconv.Itoa(pid)
+
+	entries, err := pfs.readDir(p, procdir_per_process_ns)
+	if err != nil {
+		return ProcNamespaces{}, err
+	}
+
+	ns := ProcNamespaces{Pid: pid}
+	fields := ns.fields()
+
+	for _, e := range entries {
+		f, ok := fields[e.Name()]
+		if !ok {
+			continue
+		}
+
+		target, err := pfs.readLink(p, procdir_per_process_ns, e.Name())
+		if errors.Is(err, fs.ErrNotExist) && !isProcessGone(err) {
+			// Zombies have lost most of their namespaces, and
+			// pid_for_children dangles until the first child of an
+			// unshare(CLONE_NEWPID).
+			continue
+		}
+		if err != nil {
+			return ProcNamespaces{}, err
+		}
+
+		// e.g. "net:[4026531833]"
+		i := strings.Index(target, ":[")
+		if i < 0 || !strings.HasSuffix(target, "]") {
+			return ProcNamespaces{}, &ParseError{File: path.Join(p, procdir_per_process_ns, e.Name()), Err: errUnexpectedFormat(target)}
+		}
+
+		v, err := strconv.ParseUint(target[i+2:len(target)-1], 10, 64)
+		if err != nil {
+			return ProcNamespaces{}, &ParseError{File: path.Join(p, procdir_per_process_ns, e.Name()), Err: err}
+		}
+		*f = v
+	}
+
+	return ns, nil
+}
+
+// Namespace is a namespace and the processes that are members of it.
+type Namespace struct {
+	Type  string // e.g. "net" or "pid"
+	Inode uint64
+	Pids  []int // in ascending order
+}
+
+// GetNamespaces returns every namespace that has a process as a member.
+func GetNamespaces() ([]Namespace, error) {
+	return defaultFS.GetNamespaces()
+}
+
+// GetNamespaces returns every namespace that has a process as a member,
+// ordered by type and inode. The namespaces of the children of a process
+// (pid_for_children and time_for_children) are not memberships and are
+// not counted.
+//
+// Processes that exit during the scan are skipped, and zombies are only
+// counted in the namespaces they still have. If some other processes cannot
+// be read, e.g. with ErrPermission, the namespaces found are returned
+// together with a *ScanError.
+func (pfs FS) GetNamespaces() ([]Namespace, error) {
+	return pfs.GetNamespacesContext(context.Background(), ScanOptions{})
+}
+
+// GetNamespacesContext is like GetNamespaces, with the scan configured by opts, but stops when ctx is done.
+func GetNamespacesContext(ctx context.Context, opts ScanOptions) ([]Namespace, error) {
+	return defaultFS.GetNamespacesContext(ctx, opts)
+}
+
+// GetNamespacesContext is like GetNamespaces, with the scan configured by opts, but stops when ctx is done.
+// In that case, the namespaces found so far are returned together with ctx.Err().
+func (pfs FS) GetNamespacesContext(ctx context.Context, opts ScanOptions) ([]Namespace, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pids, err := pfs.pids()
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		typ   string
+		inode uint64
+	}
+	members := make(map[key][]int)
+
+	read := func(i int) (interface{}, error) {
+		return pfs.GetProcessNamespaces(pids[i])
+	}
+
+	collect := func(i int, v interface{}) {
+		ns := v.(ProcNamespaces)
+
+		for typ, f := range ns.fields() {
+			if *f == 0 || strings.HasSuffix(typ, "_for_children") {
+				continue
+			}
+
+			k := key{typ: typ, inode: *f}
+			members[k] = append(members[k], ns.Pid)
+		}
+	}
+
+	errs, err := scan(ctx, len(pids), opts.Workers, read, collect)
+
+	namespaces := make([]Namespace, 0, len(members))
+	for k, m := range members {
+		sort.Ints(m)
+		namespaces = append(namespaces, Namespace{Type: k.typ, Inode: k.inode, Pids: m})
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		if namespaces[i].Type != namespaces[j].Type {
+			return namespaces[i].Type < namespaces[j].Type
+		}
+		return namespaces[i].Inode < namespaces[j].Inode
+	})
+
+	if err != nil {
+		return namespaces, err
+	}
+
+	return namespaces, newScanError(pids, errs)
+}
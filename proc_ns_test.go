@@ -0,0 +1,177 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// testNsFS is a procfs fixture with a process in the initial namespaces (1),
+// a container process in its own net, pid, mnt and uts namespaces (10), a
+// process of an old kernel without time namespaces (20), a process of
+// another user (30), a zombie that only kept its user namespace (40) and a
+// process that unshared its PID namespace but has no child yet (50).
+var testNsFS = linkFS{
+	FS: deniedFS{
+		FS: fstest.MapFS{
+			"1/ns/cgroup":             {},
+			"1/ns/ipc":                {},
+			"1/ns/mnt":                {},
+			"1/ns/net":                {},
+			"1/ns/pid":                {},
+			"1/ns/pid_for_children":   {},
+			"1/ns/time":               {},
+			"1/ns/time_for_children":  {},
+			"1/ns/user":               {},
+			"1/ns/uts":                {},
+			"10/ns/cgroup":            {},
+			"10/ns/ipc":               {},
+			"10/ns/mnt":               {},
+			"10/ns/net":               {},
+			"10/ns/pid":               {},
+			"10/ns/pid_for_children":  {},
+			"10/ns/time":              {},
+			"10/ns/time_for_children": {},
+			"10/ns/user":              {},
+			"10/ns/uts":               {},
+			"20/ns/net":               {},
+			"20/ns/pid":               {},
+			"30/ns/net":               {},
+			"40/ns/net":               {},
+			"40/ns/pid":               {},
+			"40/ns/user":              {},
+			"50/ns/pid":               {},
+			"50/ns/pid_for_children":  {},
+		},
+		name: "30/ns",
+	},
+	links: map[string]string{
+		"1/ns/cgroup":             "cgroup:[4026531835]",
+		"1/ns/ipc":                "ipc:[4026531839]",
+		"1/ns/mnt":                "mnt:[4026531841]",
+		"1/ns/net":                "net:[4026531840]",
+		"1/ns/pid":                "pid:[4026531836]",
+		"1/ns/pid_for_children":   "pid:[4026531836]",
+		"1/ns/time":               "time:[4026531834]",
+		"1/ns/time_for_children":  "time:[4026531834]",
+		"1/ns/user":               "user:[4026531837]",
+		"1/ns/uts":                "uts:[4026531838]",
+		"10/ns/cgroup":            "cgroup:[4026531835]",
+		"10/ns/ipc":               "ipc:[4026531839]",
+		"10/ns/mnt":               "mnt:[4026532201]",
+		"10/ns/net":               "net:[4026532204]",
+		"10/ns/pid":               "pid:[4026532203]",
+		"10/ns/pid_for_children":  "pid:[4026532203]",
+		"10/ns/time":              "time:[4026531834]",
+		"10/ns/time_for_children": "time:[4026531834]",
+		"10/ns/user":              "user:[4026531837]",
+		"10/ns/uts":               "uts:[4026532202]",
+		"20/ns/net":               "net:[4026531840]",
+		"20/ns/pid":               "pid:[4026531836]",
+		"40/ns/user":              "user:[4026531837]",
+		"50/ns/pid":               "pid:[4026531836]",
+	},
+}
+
+// TestGetProcessNamespaces tests reading the namespaces of a process.
+func TestGetProcessNamespaces(t *testing.T) {
+	fs := NewFSFromFS(testNsFS)
+
+	ns, err := fs.GetProcessNamespaces(10)
+	want := ProcNamespaces{
+		Pid:             10,
+		Cgroup:          4026531835,
+		IPC:             4026531839,
+		Mnt:             4026532201,
+		Net:             4026532204,
+		PID:             4026532203,
+		PIDForChildren:  4026532203,
+		Time:            4026531834,
+		TimeForChildren: 4026531834,
+		User:            4026531837,
+		UTS:             4026532202,
+	}
+	if err != nil || ns != want {
+		t.Errorf("GetProcessNamespaces(10) = %+v, %v; want %+v", ns, err, want)
+	}
+
+	ns, err = fs.GetProcessNamespaces(20)
+	want = ProcNamespaces{Pid: 20, Net: 4026531840, PID: 4026531836}
+	if err != nil || ns != want {
+		t.Errorf("GetProcessNamespaces(20) = %+v, %v; want %+v", ns, err, want)
+	}
+
+	ns, err = fs.GetProcessNamespaces(40)
+	want = ProcNamespaces{Pid: 40, User: 4026531837}
+	if err != nil || ns != want {
+		t.Errorf("GetProcessNamespaces(40) = %+v, %v; want %+v", ns, err, want)
+	}
+
+	ns, err = fs.GetProcessNamespaces(50)
+	want = ProcNamespaces{Pid: 50, PID: 4026531836}
+	if err != nil || ns != want {
+		t.Errorf("GetProcessNamespaces(50) = %+v, %v; want %+v", ns, err, want)
+	}
+
+	if _, err := fs.GetProcessNamespaces(30); !errors.Is(err, ErrPermission) {
+		t.Errorf("GetProcessNamespaces(30) = %v; want %v", err, ErrPermission)
+	}
+}
+
+// TestGetNamespaces tests grouping the processes by namespace.
+func TestGetNamespaces(t *testing.T) {
+	namespaces, err := NewFSFromFS(testNsFS).GetNamespacesContext(context.Background(), ScanOptions{Workers: 2})
+
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) || len(scanErr.Errors) != 1 || scanErr.Errors[0].Pid != 30 {
+		t.Errorf("GetNamespacesContext() error = %v; want only PID 30", err)
+	}
+
+	want := []Namespace{
+		{Type: "cgroup", Inode: 4026531835, Pids: []int{1, 10}},
+		{Type: "ipc", Inode: 4026531839, Pids: []int{1, 10}},
+		{Type: "mnt", Inode: 4026531841, Pids: []int{1}},
+		{Type: "mnt", Inode: 4026532201, Pids: []int{10}},
+		{Type: "net", Inode: 4026531840, Pids: []int{1, 20}},
+		{Type: "net", Inode: 4026532204, Pids: []int{10}},
+		{Type: "pid", Inode: 4026531836, Pids: []int{1, 20, 50}},
+		{Type: "pid", Inode: 4026532203, Pids: []int{10}},
+		{Type: "time", Inode: 4026531834, Pids: []int{1, 10}},
+		{Type: "user", Inode: 4026531837, Pids: []int{1, 10, 40}},
+		{Type: "uts", Inode: 4026531838, Pids: []int{1}},
+		{Type: "uts", Inode: 4026532202, Pids: []int{10}},
+	}
+	if !reflect.DeepEqual(namespaces, want) {
+		t.Errorf("GetNamespacesContext() = %+v; want %+v", namespaces, want)
+	}
+}
+
+// TestGetProcessNamespacesLive tests reading the namespaces of the running
+// process and finding it in the system-wide listing.
+func TestGetProcessNamespacesLive(t *testing.T) {
+	ns, err := GetProcessNamespaces(os.Getpid())
+	if err != nil {
+		t.Fatalf("GetProcessNamespaces(%v): %v", os.Getpid(), err)
+	}
+
+	if ns.Net == 0 || ns.PID == 0 || ns.Mnt == 0 {
+		t.Errorf("GetProcessNamespaces(%v) = %+v", os.Getpid(), ns)
+	}
+
+	namespaces, _ := GetNamespaces()
+
+	var found bool
+	for _, n := range namespaces {
+		if n.Type == "net" && n.Inode == ns.Net {
+			for _, pid := range n.Pids {
+				found = found || pid == os.Getpid()
+			}
+		}
+	}
+	if !found {
+		t.Errorf("GetNamespaces() has no net namespace %v with PID %v", ns.Net, os.Getpid())
+	}
+}
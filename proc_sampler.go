@@ -0,0 +1,125 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ProcessUsage contains the activity of a process between two samples of a ProcSampler.
+type ProcessUsage struct {
+	Procstat // the current sample
+
+	// New is true if the process started (or its PID was reused) since the
+	// previous sample. The rates and deltas of new processes are zero.
+	New bool
+
+	// Interval is the time elapsed since the previous sample.
+	Interval time.Duration
+
+	CPUPercent    float64 // user and system time, in percent of one CPU
+	UserPercent   float64 // user time, in percent of one CPU
+	SystemPercent float64 // system time, in percent of one CPU
+	MinfltRate    float64 // minor faults per second
+	MajfltRate    float64 // major faults per second
+	CutimeDelta   uint64  // user time of waited-for children (clock ticks)
+	CstimeDelta   uint64  // system time of waited-for children (clock ticks)
+}
+
+// procKey identifies a process across samples, so that reused PIDs are
+// not mistaken for the process that previously had them.
+type procKey struct {
+	pid       int
+	starttime uint64
+}
+
+// ProcSampler computes per-process CPU usage and rates from successive
+// samples of the process table. It is not safe for concurrent use.
+type ProcSampler struct {
+	pfs  FS
+	opts StatScanOptions
+	hz   uint64
+	prev map[procKey]Procstat
+	last time.Time
+}
+
+// NewProcSampler returns a ProcSampler of the processes in the default /proc.
+func NewProcSampler(opts StatScanOptions) *ProcSampler {
+	return defaultFS.NewProcSampler(opts)
+}
+
+// NewProcSampler returns a ProcSampler of the processes in the FS.
+// opts configures the scan of every sample.
+func (pfs FS) NewProcSampler(opts StatScanOptions) *ProcSampler {
+	return &ProcSampler{pfs: pfs, opts: opts, hz: pfs.auxvUnits().ClockTicks}
+}
+
+// Sample scans the process table and returns the activity of every process
+// since the previous call. On the first call, every process is New.
+// As with GetPerProcessStat, a *ScanError is returned with the processes that were read.
+func (s *ProcSampler) Sample() ([]ProcessUsage, error) {
+	return s.SampleContext(context.Background())
+}
+
+// SampleContext is like Sample, but stops the scan when ctx is done.
+// In that case, ctx.Err() is returned and the sample is discarded.
+func (s *ProcSampler) SampleContext(ctx context.Context) ([]ProcessUsage, error) {
+	pps, err := s.pfs.GetPerProcessStatContext(ctx, s.opts)
+
+	var scanErr *ScanError
+	if err != nil && !errors.As(err, &scanErr) {
+		return nil, err
+	}
+
+	return s.Update(pps, time.Now()), err
+}
+
+// Update records pps, the process table sampled at t, and returns the
+// activity of every process since the previous sample. Processes that
+// exited since the previous sample are forgotten.
+func (s *ProcSampler) Update(pps []Procstat, t time.Time) []ProcessUsage {
+	interval := t.Sub(s.last)
+	secs := interval.Seconds()
+
+	hz := s.hz
+	if hz == 0 {
+		hz = userHZ
+	}
+
+	cur := make(map[procKey]Procstat, len(pps))
+	usage := make([]ProcessUsage, 0, len(pps))
+
+	for _, p := range pps {
+		k := procKey{pid: p.Pid, starttime: p.Starttime}
+		cur[k] = p
+
+		u := ProcessUsage{Procstat: p}
+
+		prev, ok := s.prev[k]
+		if !ok || secs <= 0 {
+			u.New = !ok
+			usage = append(usage, u)
+			continue
+		}
+
+		u.Interval = interval
+
+		user := float64(delta(prev.Utime, p.Utime)) / float64(hz)
+		system := float64(delta(prev.Stime, p.Stime)) / float64(hz)
+
+		u.UserPercent = user / secs * 100
+		u.SystemPercent = system / secs * 100
+		u.CPUPercent = u.UserPercent + u.SystemPercent
+		u.MinfltRate = float64(delta(prev.Minflt, p.Minflt)) / secs
+		u.MajfltRate = float64(delta(prev.Majflt, p.Majflt)) / secs
+		u.CutimeDelta = delta(prev.Cutime, p.Cutime)
+		u.CstimeDelta = delta(prev.Cstime, p.Cstime)
+
+		usage = append(usage, u)
+	}
+
+	s.prev = cur
+	s.last = t
+
+	return usage
+}
@@ -0,0 +1,87 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestProcSamplerUpdate tests the per-process rates computed from two samples,
+// including started, exited and reused PIDs.
+func TestProcSamplerUpdate(t *testing.T) {
+	s := NewFSFromFS(testProcFS).NewProcSampler(StatScanOptions{})
+	t0 := time.Unix(1700000000, 0)
+
+	first := s.Update([]Procstat{
+		{Pid: 1, Starttime: 27, Utime: 1000, Stime: 500, Minflt: 100, Majflt: 10, Cutime: 40, Cstime: 4},
+		{Pid: 5, Starttime: 100, Utime: 50},
+		{Pid: 9, Starttime: 200, Utime: 70},
+	}, t0)
+
+	for _, u := range first {
+		if !u.New || u.CPUPercent != 0 {
+			t.Errorf("Update() first sample = %+v; want New with no rates", u)
+		}
+	}
+
+	second := s.Update([]Procstat{
+		{Pid: 1, Starttime: 27, Utime: 1150, Stime: 550, Minflt: 120, Majflt: 14, Cutime: 50, Cstime: 6},
+		{Pid: 5, Starttime: 500, Utime: 3},
+		{Pid: 7, Starttime: 510, Utime: 8},
+	}, t0.Add(2*time.Second))
+
+	if len(second) != 3 {
+		t.Fatalf("Update() = %+v; want 3 processes", second)
+	}
+
+	want := ProcessUsage{
+		Procstat:      Procstat{Pid: 1, Starttime: 27, Utime: 1150, Stime: 550, Minflt: 120, Majflt: 14, Cutime: 50, Cstime: 6},
+		Interval:      2 * time.Second,
+		CPUPercent:    100,
+		UserPercent:   75,
+		SystemPercent: 25,
+		MinfltRate:    10,
+		MajfltRate:    2,
+		CutimeDelta:   10,
+		CstimeDelta:   2,
+	}
+	if !reflect.DeepEqual(second[0], want) {
+		t.Errorf("Update() PID 1 = %+v; want %+v", second[0], want)
+	}
+
+	// PID 5 was reused by another process, PID 7 is new.
+	for _, u := range second[1:] {
+		if !u.New || u.CPUPercent != 0 || u.Interval != 0 {
+			t.Errorf("Update() PID %v = %+v; want New with no rates", u.Pid, u)
+		}
+	}
+
+	// PID 9 exited and is forgotten: it is new if it shows up again.
+	third := s.Update([]Procstat{{Pid: 9, Starttime: 200, Utime: 90}}, t0.Add(3*time.Second))
+	if len(third) != 1 || !third[0].New {
+		t.Errorf("Update() of an exited PID = %+v; want New", third)
+	}
+}
+
+// TestProcSamplerSample tests sampling the process table of a FS.
+func TestProcSamplerSample(t *testing.T) {
+	s := NewFSFromFS(testProcFS).NewProcSampler(StatScanOptions{ScanOptions: ScanOptions{Workers: 2}})
+
+	usage, err := s.Sample()
+	if err != nil || len(usage) != 2 || !usage[0].New || usage[0].Pid != 1 {
+		t.Errorf("Sample() = %+v, %v", usage, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if usage, err := s.SampleContext(ctx); usage != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("SampleContext() with a canceled ctx = %+v, %v; want %v", usage, err, context.Canceled)
+	}
+
+	usage, err = s.Sample()
+	if err != nil || len(usage) != 2 || usage[0].New || usage[0].Interval <= 0 {
+		t.Errorf("Sample() = %+v, %v", usage, err)
+	}
+}
@@ -0,0 +1,127 @@
+package lpfs
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const procdir_per_process_statm string = "statm"
+
+// ProcStatm contains process memory sizes available in /proc/<pid>/statm, in bytes.
+// Reading statm is much cheaper than /proc/<pid>/stat when only memory is needed.
+type ProcStatm struct {
+	Pid      int
+	Size     uint64 // total program size, as Procstat.Vsize
+	Resident uint64 // resident set size, as Procstat.Rss
+	Shared   uint64 // resident file-backed and shared memory
+	Text     uint64 // code
+	Data     uint64 // data and stack
+}
+
+// GetProcessStatm returns the memory sizes of a giving process.
+func GetProcessStatm(pid int) (ProcStatm, error) {
+	return defaultFS.GetProcessStatm(pid)
+}
+
+// GetProcessStatm returns the memory sizes of a giving process.
+func (pfs FS) GetProcessStatm(pid int) (ProcStatm, error) {
+	return pfs.processStatm(pid, pfs.auxvUnits().PageSize)
+}
+
+// GetPerProcessStatm returns the memory sizes of all living processes in the system.
+func GetPerProcessStatm() ([]ProcStatm, error) {
+	return defaultFS.GetPerProcessStatm()
+}
+
+// GetPerProcessStatm returns the memory sizes of all living processes in the system.
+// Processes that exit during the scan are skipped. If some other processes
+// cannot be read, the remaining ones are returned together with a *ScanError.
+func (pfs FS) GetPerProcessStatm() ([]ProcStatm, error) {
+	return pfs.GetPerProcessStatmContext(context.Background(), ScanOptions{})
+}
+
+// GetPerProcessStatmContext is like GetPerProcessStatm, with the scan configured by opts, but stops when ctx is done.
+func GetPerProcessStatmContext(ctx context.Context, opts ScanOptions) ([]ProcStatm, error) {
+	return defaultFS.GetPerProcessStatmContext(ctx, opts)
+}
+
+// GetPerProcessStatmContext is like GetPerProcessStatm, with the scan configured by opts, but stops when ctx is done.
+// In that case, the processes read so far are returned together with ctx.Err().
+func (pfs FS) GetPerProcessStatmContext(ctx context.Context, opts ScanOptions) ([]ProcStatm, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pids, err := pfs.pids()
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := pfs.auxvUnits().PageSize
+
+	psm_s := make([]ProcStatm, len(pids))
+	found := make([]bool, len(pids))
+
+	read := func(i int) (interface{}, error) {
+		return pfs.processStatm(pids[i], pageSize)
+	}
+
+	collect := func(i int, v interface{}) {
+		psm_s[i] = v.(ProcStatm)
+		found[i] = true
+	}
+
+	errs, err := scan(ctx, len(pids), opts.Workers, read, collect)
+
+	psm := make([]ProcStatm, 0, len(pids))
+	for _, i := range foundIndices(found) {
+		psm = append(psm, psm_s[i])
+	}
+
+	if err != nil {
+		return psm, err
+	}
+
+	return psm, newScanError(pids, errs)
+}
+
+// processStatm reads /proc/<pid>/statm, converting pages of pageSize bytes to bytes.
+func (pfs FS) processStatm(pid int, pageSize uint64) (ProcStatm, error) {
+	statmFile := path.Join(strconv.Itoa(pid), procdir_per_process_statm)
+
+	dat, err := pfs.readFile(statmFile)
+	if err != nil {
+		return ProcStatm{}, err
+	}
+
+	return parseProcStatm(statmFile, pid, dat, pageSize)
+}
+
+// procstatmFields are the names, as in proc(5), of the /proc/<pid>/statm fields.
+// lib and dt have been unused since Linux 2.6 and are always 0.
+var procstatmFields = []string{"size", "resident", "shared", "text", "lib", "data", "dt"}
+
+// parseProcStatm parses the content of a /proc/<pid>/statm file named file.
+func parseProcStatm(file string, pid int, dat []byte, pageSize uint64) (ProcStatm, error) {
+	// e.g. "660 313 287 5 0 123 0"
+	dat_s := strings.Fields(string(dat))
+	if len(dat_s) != len(procstatmFields) {
+		return ProcStatm{}, &ParseError{File: file, Err: errUnexpectedFormat(string(dat))}
+	}
+
+	p := ProcStatm{Pid: pid}
+	var lib, dt uint64
+	fields := []*uint64{&p.Size, &p.Resident, &p.Shared, &p.Text, &lib, &p.Data, &dt}
+
+	for i, f := range fields {
+		v, err := strconv.ParseUint(dat_s[i], 10, 64)
+		if err != nil {
+			return ProcStatm{}, &ParseError{File: file, Field: procstatmFields[i], Err: err}
+		}
+		*f = v * pageSize
+	}
+
+	return p, nil
+}
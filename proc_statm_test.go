@@ -0,0 +1,85 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// testStatmFS is a procfs fixture with 16 KiB pages, a malformed process and
+// a process that has exited.
+var testStatmFS = fstest.MapFS{
+	"self/auxv":  {Data: makeAuxv(atPagesz, 16384)},
+	"1/statm":    {Data: []byte("42101 3199 2020 245 0 5100 0\n")},
+	"10/statm":   {Data: []byte("0 0 0 0 0 0 0\n")},
+	"100/statm":  {Data: []byte("1 2 3\n")},
+	"200/status": {Data: []byte("Name:\tgone\n")},
+}
+
+// TestGetProcessStatm tests parsing /proc/<pid>/statm.
+func TestGetProcessStatm(t *testing.T) {
+	fs := NewFSFromFS(testStatmFS)
+
+	p, err := fs.GetProcessStatm(1)
+	want := ProcStatm{Pid: 1, Size: 42101 * 16384, Resident: 3199 * 16384, Shared: 2020 * 16384, Text: 245 * 16384, Data: 5100 * 16384}
+	if err != nil || p != want {
+		t.Errorf("GetProcessStatm(1) = %+v, %v; want %+v", p, err, want)
+	}
+
+	// The page size is read once per FS.
+	fsys := fstest.MapFS{"self/auxv": testStatmFS["self/auxv"], "1/statm": testStatmFS["1/statm"]}
+	cached := NewFSFromFS(fsys)
+	cached.GetProcessStatm(1)
+	delete(fsys, "self/auxv")
+	if p, err := cached.GetProcessStatm(1); err != nil || p != want {
+		t.Errorf("GetProcessStatm(1) without self/auxv = %+v, %v; want %+v", p, err, want)
+	}
+
+	var perr *ParseError
+	if _, err := fs.GetProcessStatm(100); !errors.As(err, &perr) || perr.File != "100/statm" {
+		t.Errorf("GetProcessStatm(100) = %v; want ParseError for 100/statm", err)
+	}
+}
+
+// TestGetPerProcessStatm tests that the memory-only scan skips exited
+// processes and reports the others that failed in a ScanError.
+func TestGetPerProcessStatm(t *testing.T) {
+	fs := NewFSFromFS(vanishedFS{FS: testStatmFS, pid: "200"})
+
+	for _, workers := range []int{1, 4} {
+		psm, err := fs.GetPerProcessStatmContext(context.Background(), ScanOptions{Workers: workers})
+
+		var scanErr *ScanError
+		if !errors.As(err, &scanErr) || len(scanErr.Errors) != 1 || scanErr.Errors[0].Pid != 100 {
+			t.Errorf("Workers: %v: GetPerProcessStatmContext() error = %v; want only PID 100", workers, err)
+		}
+
+		want := []ProcStatm{
+			{Pid: 1, Size: 42101 * 16384, Resident: 3199 * 16384, Shared: 2020 * 16384, Text: 245 * 16384, Data: 5100 * 16384},
+			{Pid: 10},
+		}
+		if !reflect.DeepEqual(psm, want) {
+			t.Errorf("Workers: %v: GetPerProcessStatmContext() = %+v; want %+v", workers, psm, want)
+		}
+	}
+}
+
+// TestGetProcessStatmLive tests reading the statm of the running process.
+func TestGetProcessStatmLive(t *testing.T) {
+	u, err := GetUnits()
+	if err != nil {
+		t.Fatalf("GetUnits(): %v", err)
+	}
+
+	p, err := GetProcessStatm(os.Getpid())
+	if err != nil {
+		t.Fatalf("GetProcessStatm(%v): %v", os.Getpid(), err)
+	}
+
+	if p.Pid != os.Getpid() || p.Resident == 0 || p.Resident%u.PageSize != 0 || p.Size < p.Resident {
+		t.Errorf("GetProcessStatm(%v) = %+v", os.Getpid(), p)
+	}
+}
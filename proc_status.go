@@ -0,0 +1,374 @@
+package lpfs
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const procdir_per_process_status string = "status"
+
+// ProcStatus contains process status available in /proc/<pid>/status.
+//
+// Memory sizes are in bytes, signal and capability sets are bitmasks (bit n
+// is signal n+1 or capability n) and CPU and memory node lists are sets.
+// Fields not emitted by the running kernel are left zero.
+type ProcStatus struct {
+	Name      string
+	Umask     uint32
+	State     string // e.g. "S (sleeping)"
+	Tgid      int
+	Ngid      int
+	Pid       int
+	Ppid      int
+	TracerPid int
+	UIDs      [4]uint32 // real, effective, saved set and filesystem UIDs
+	GIDs      [4]uint32 // real, effective, saved set and filesystem GIDs
+	FDSize    uint64
+	Groups    []uint32
+
+	// Thread group, process, process group and session IDs in each of the
+	// PID namespaces the process is a member of, outermost first.
+	NStgid []int
+	NSpid  []int
+	NSpgid []int
+	NSsid  []int
+
+	Kthread      bool
+	VmPeak       uint64
+	VmSize       uint64
+	VmLck        uint64
+	VmPin        uint64
+	VmHWM        uint64
+	VmRSS        uint64
+	RssAnon      uint64
+	RssFile      uint64
+	RssShmem     uint64
+	VmData       uint64
+	VmStk        uint64
+	VmExe        uint64
+	VmLib        uint64
+	VmPTE        uint64
+	VmSwap       uint64
+	HugetlbPages uint64
+	CoreDumping  bool
+	Threads      uint64
+
+	SigQueued     uint64 // queued signals of the real UID
+	SigQueueLimit uint64 // limit on queued signals
+	SigPnd        uint64
+	ShdPnd        uint64
+	SigBlk        uint64
+	SigIgn        uint64
+	SigCgt        uint64
+
+	CapInh uint64
+	CapPrm uint64
+	CapEff uint64
+	CapBnd uint64
+	CapAmb uint64
+
+	NoNewPrivs     bool
+	Seccomp        int // 0 disabled, 1 strict, 2 filter
+	SeccompFilters int
+
+	CpusAllowed CPUSet
+	MemsAllowed CPUSet // NUMA nodes
+
+	VoluntaryCtxtSwitches    uint64
+	NonvoluntaryCtxtSwitches uint64
+
+	// Other contains the lines not parsed into the fields above, keyed by
+	// their name, e.g. "Speculation_Store_Bypass".
+	Other map[string]string
+}
+
+// CPUSet is a set of CPU (or memory node) numbers.
+type CPUSet map[int]bool
+
+// List returns the numbers in the set in ascending order.
+func (s CPUSet) List() []int {
+	l := make([]int, 0, len(s))
+	for n := range s {
+		l = append(l, n)
+	}
+	sort.Ints(l)
+
+	return l
+}
+
+// GetProcessStatus returns status information of a giving process.
+func GetProcessStatus(pid int) (ProcStatus, error) {
+	return defaultFS.GetProcessStatus(pid)
+}
+
+// GetProcessStatus returns status information of a giving process.
+func (pfs FS) GetProcessStatus(pid int) (ProcStatus, error) {
+	statusFile := path.Join(strconv.Itoa(pid), procdir_per_process_status)
+
+	dat, err := pfs.readFile(statusFile)
+	if err != nil {
+		return ProcStatus{}, err
+	}
+
+	return parseProcStatus(statusFile, dat)
+}
+
+// parseProcStatus parses the content of a /proc/<pid>/status file named file.
+func parseProcStatus(file string, dat []byte) (ProcStatus, error) {
+	var p ProcStatus
+
+	for n, line := range strings.Split(string(dat), "\n") {
+		// e.g. "VmPeak:	    2640 kB" or "Uid:	0	0	0	0"
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+
+		key, val := line[:i], strings.TrimSpace(line[i+1:])
+		if err := p.set(key, val); err != nil {
+			return ProcStatus{}, &ParseError{File: file, Field: key, Line: n + 1, Err: err}
+		}
+	}
+
+	return p, nil
+}
+
+// set parses the value of the /proc/<pid>/status line named key into p.
+func (p *ProcStatus) set(key, val string) error {
+	var err error
+
+	switch key {
+	case "Name":
+		p.Name = val
+	case "Umask":
+		var v uint64
+		v, err = strconv.ParseUint(val, 8, 32)
+		p.Umask = uint32(v)
+	case "State":
+		p.State = val
+	case "Tgid":
+		p.Tgid, err = strconv.Atoi(val)
+	case "Ngid":
+		p.Ngid, err = strconv.Atoi(val)
+	case "Pid":
+		p.Pid, err = strconv.Atoi(val)
+	case "PPid":
+		p.Ppid, err = strconv.Atoi(val)
+	case "TracerPid":
+		p.TracerPid, err = strconv.Atoi(val)
+	case "Uid":
+		p.UIDs, err = parseIDs(val)
+	case "Gid":
+		p.GIDs, err = parseIDs(val)
+	case "FDSize":
+		p.FDSize, err = strconv.ParseUint(val, 10, 64)
+	case "Groups":
+		p.Groups, err = parseUint32s(val)
+	case "NStgid":
+		p.NStgid, err = parseInts(val)
+	case "NSpid":
+		p.NSpid, err = parseInts(val)
+	case "NSpgid":
+		p.NSpgid, err = parseInts(val)
+	case "NSsid":
+		p.NSsid, err = parseInts(val)
+	case "Kthread":
+		p.Kthread, err = parseFlag(val)
+	case "VmPeak":
+		p.VmPeak, err = parseKB(val)
+	case "VmSize":
+		p.VmSize, err = parseKB(val)
+	case "VmLck":
+		p.VmLck, err = parseKB(val)
+	case "VmPin":
+		p.VmPin, err = parseKB(val)
+	case "VmHWM":
+		p.VmHWM, err = parseKB(val)
+	case "VmRSS":
+		p.VmRSS, err = parseKB(val)
+	case "RssAnon":
+		p.RssAnon, err = parseKB(val)
+	case "RssFile":
+		p.RssFile, err = parseKB(val)
+	case "RssShmem":
+		p.RssShmem, err = parseKB(val)
+	case "VmData":
+		p.VmData, err = parseKB(val)
+	case "VmStk":
+		p.VmStk, err = parseKB(val)
+	case "VmExe":
+		p.VmExe, err = parseKB(val)
+	case "VmLib":
+		p.VmLib, err = parseKB(val)
+	case "VmPTE":
+		p.VmPTE, err = parseKB(val)
+	case "VmSwap":
+		p.VmSwap, err = parseKB(val)
+	case "HugetlbPages":
+		p.HugetlbPages, err = parseKB(val)
+	case "CoreDumping":
+		p.CoreDumping, err = parseFlag(val)
+	case "Threads":
+		p.Threads, err = strconv.ParseUint(val, 10, 64)
+	case "SigQ":
+		// e.g. "0/23870"
+		i := strings.Index(val, "/")
+		if i < 0 {
+			return errUnexpectedFormat(val)
+		}
+		if p.SigQueued, err = strconv.ParseUint(val[:i], 10, 64); err != nil {
+			return err
+		}
+		p.SigQueueLimit, err = strconv.ParseUint(val[i+1:], 10, 64)
+	case "SigPnd":
+		p.SigPnd, err = strconv.ParseUint(val, 16, 64)
+	case "ShdPnd":
+		p.ShdPnd, err = strconv.ParseUint(val, 16, 64)
+	case "SigBlk":
+		p.SigBlk, err = strconv.ParseUint(val, 16, 64)
+	case "SigIgn":
+		p.SigIgn, err = strconv.ParseUint(val, 16, 64)
+	case "SigCgt":
+		p.SigCgt, err = strconv.ParseUint(val, 16, 64)
+	case "CapInh":
+		p.CapInh, err = strconv.ParseUint(val, 16, 64)
+	case "CapPrm":
+		p.CapPrm, err = strconv.ParseUint(val, 16, 64)
+	case "CapEff":
+		p.CapEff, err = strconv.ParseUint(val, 16, 64)
+	case "CapBnd":
+		p.CapBnd, err = strconv.ParseUint(val, 16, 64)
+	case "CapAmb":
+		p.CapAmb, err = strconv.ParseUint(val, 16, 64)
+	case "NoNewPrivs":
+		p.NoNewPrivs, err = parseFlag(val)
+	case "Seccomp":
+		p.Seccomp, err = strconv.Atoi(val)
+	case "Seccomp_filters":
+		p.SeccompFilters, err = strconv.Atoi(val)
+	case "Cpus_allowed_list":
+		p.CpusAllowed, err = parseCPUList(val)
+	case "Mems_allowed_list":
+		p.MemsAllowed, err = parseCPUList(val)
+	case "voluntary_ctxt_switches":
+		p.VoluntaryCtxtSwitches, err = strconv.ParseUint(val, 10, 64)
+	case "nonvoluntary_ctxt_switches":
+		p.NonvoluntaryCtxtSwitches, err = strconv.ParseUint(val, 10, 64)
+	default:
+		if p.Other == nil {
+			p.Other = make(map[string]string)
+		}
+		p.Other[key] = val
+	}
+
+	return err
+}
+
+// parseKB parses a size such as "2640 kB" into bytes.
+func parseKB(s string) (uint64, error) {
+	dat_s := strings.Fields(s)
+	if len(dat_s) == 0 || len(dat_s) > 2 || (len(dat_s) == 2 && dat_s[1] != "kB") {
+		return 0, errUnexpectedFormat(s)
+	}
+
+	v, err := strconv.ParseUint(dat_s[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return v * 1024, nil
+}
+
+// parseFlag parses a "0" or "1" value.
+func parseFlag(s string) (bool, error) {
+	switch s {
+	case "0":
+		return false, nil
+	case "1":
+		return true, nil
+	}
+
+	return false, errUnexpectedFormat(s)
+}
+
+// parseIDs parses the real, effective, saved set and filesystem IDs of a
+// "Uid" or "Gid" line.
+func parseIDs(s string) ([4]uint32, error) {
+	var ids [4]uint32
+
+	l, err := parseUint32s(s)
+	if err != nil {
+		return ids, err
+	}
+	if len(l) != len(ids) {
+		return ids, errUnexpectedFormat(s)
+	}
+	copy(ids[:], l)
+
+	return ids, nil
+}
+
+// parseUint32s parses a whitespace separated list of unsigned 32-bit values.
+func parseUint32s(s string) ([]uint32, error) {
+	var l []uint32
+
+	for _, f := range strings.Fields(s) {
+		v, err := strconv.ParseUint(f, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, uint32(v))
+	}
+
+	return l, nil
+}
+
+// parseInts parses a whitespace separated list of integers.
+func parseInts(s string) ([]int, error) {
+	var l []int
+
+	for _, f := range strings.Fields(s) {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, v)
+	}
+
+	return l, nil
+}
+
+// parseCPUList parses a list such as "0-3,8,10-11" into a set.
+func parseCPUList(s string) (CPUSet, error) {
+	set := make(CPUSet)
+	if s == "" {
+		return set, nil
+	}
+
+	for _, r := range strings.Split(s, ",") {
+		lo, hi := r, r
+		if i := strings.Index(r, "-"); i >= 0 {
+			lo, hi = r[:i], r[i+1:]
+		}
+
+		first, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, err
+		}
+		last, err := strconv.Atoi(hi)
+		if err != nil {
+			return nil, err
+		}
+		if first < 0 || last < first {
+			return nil, errUnexpectedFormat(s)
+		}
+
+		for n := first; n <= last; n++ {
+			set[n] = true
+		}
+	}
+
+	return set, nil
+}
@@ -0,0 +1,197 @@
+package lpfs
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+const testStatus = `Name:	bash
+Umask:	0022
+State:	S (sleeping)
+Tgid:	4242
+Ngid:	0
+Pid:	4242
+PPid:	4200
+TracerPid:	0
+Uid:	1000	1000	1000	1000
+Gid:	100	100	100	100
+FDSize:	256
+Groups:	10 100 998
+NStgid:	4242	17
+NSpid:	4242	17
+NSpgid:	4242	17
+NSsid:	4200	1
+Kthread:	0
+VmPeak:	    9000 kB
+VmSize:	    8804 kB
+VmLck:	       0 kB
+VmPin:	       0 kB
+VmHWM:	    5120 kB
+VmRSS:	    5012 kB
+RssAnon:	    1800 kB
+RssFile:	    3212 kB
+RssShmem:	       0 kB
+VmData:	    1904 kB
+VmStk:	     132 kB
+VmExe:	     892 kB
+VmLib:	    1984 kB
+VmPTE:	      56 kB
+VmSwap:	      12 kB
+HugetlbPages:	       0 kB
+CoreDumping:	0
+THP_enabled:	1
+Threads:	1
+SigQ:	0/23870
+SigPnd:	0000000000000000
+ShdPnd:	0000000000000000
+SigBlk:	0000000000010000
+SigIgn:	0000000000384004
+SigCgt:	000000004b813efb
+CapInh:	0000000000000000
+CapPrm:	0000000000000000
+CapEff:	0000000000000000
+CapBnd:	000001ffffffffff
+CapAmb:	0000000000000000
+NoNewPrivs:	1
+Seccomp:	2
+Seccomp_filters:	1
+Speculation_Store_Bypass:	thread vulnerable
+Cpus_allowed:	f3
+Cpus_allowed_list:	0-1,4-7
+Mems_allowed:	00000000,00000001
+Mems_allowed_list:	0
+voluntary_ctxt_switches:	150
+nonvoluntary_ctxt_switches:	3
+`
+
+// TestGetProcessStatus tests parsing /proc/<pid>/status.
+func TestGetProcessStatus(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"4242/status": {Data: []byte(testStatus)},
+	})
+
+	p, err := fs.GetProcessStatus(4242)
+	if err != nil {
+		t.Fatalf("GetProcessStatus(4242): %v", err)
+	}
+
+	want := ProcStatus{
+		Name:           "bash",
+		Umask:          0022,
+		State:          "S (sleeping)",
+		Tgid:           4242,
+		Pid:            4242,
+		Ppid:           4200,
+		UIDs:           [4]uint32{1000, 1000, 1000, 1000},
+		GIDs:           [4]uint32{100, 100, 100, 100},
+		FDSize:         256,
+		Groups:         []uint32{10, 100, 998},
+		NStgid:         []int{4242, 17},
+		NSpid:          []int{4242, 17},
+		NSpgid:         []int{4242, 17},
+		NSsid:          []int{4200, 1},
+		VmPeak:         9000 * 1024,
+		VmSize:         8804 * 1024,
+		VmHWM:          5120 * 1024,
+		VmRSS:          5012 * 1024,
+		RssAnon:        1800 * 1024,
+		RssFile:        3212 * 1024,
+		VmData:         1904 * 1024,
+		VmStk:          132 * 1024,
+		VmExe:          892 * 1024,
+		VmLib:          1984 * 1024,
+		VmPTE:          56 * 1024,
+		VmSwap:         12 * 1024,
+		Threads:        1,
+		SigQueueLimit:  23870,
+		SigBlk:         0x10000,
+		SigIgn:         0x384004,
+		SigCgt:         0x4b813efb,
+		CapBnd:         0x1ffffffffff,
+		NoNewPrivs:     true,
+		Seccomp:        2,
+		SeccompFilters: 1,
+		CpusAllowed:    CPUSet{0: true, 1: true, 4: true, 5: true, 6: true, 7: true},
+		MemsAllowed:    CPUSet{0: true},
+
+		VoluntaryCtxtSwitches:    150,
+		NonvoluntaryCtxtSwitches: 3,
+
+		Other: map[string]string{
+			"THP_enabled":              "1",
+			"Speculation_Store_Bypass": "thread vulnerable",
+			"Cpus_allowed":             "f3",
+			"Mems_allowed":             "00000000,00000001",
+		},
+	}
+
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("GetProcessStatus(4242) = %+v; want %+v", p, want)
+	}
+
+	if l := p.CpusAllowed.List(); !reflect.DeepEqual(l, []int{0, 1, 4, 5, 6, 7}) {
+		t.Errorf("CpusAllowed.List() = %v", l)
+	}
+}
+
+// TestParseCPUList tests parsing CPU lists.
+func TestParseCPUList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{"", []int{}},
+		{"0", []int{0}},
+		{"0-3", []int{0, 1, 2, 3}},
+		{"0,2,4-5", []int{0, 2, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		s, err := parseCPUList(tt.in)
+		if err != nil || !reflect.DeepEqual(s.List(), tt.want) {
+			t.Errorf("parseCPUList(%q) = %v, %v; want %v", tt.in, s.List(), err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"a", "3-1", "1-", ","} {
+		if _, err := parseCPUList(in); err == nil {
+			t.Errorf("parseCPUList(%q) = nil error; want error", in)
+		}
+	}
+}
+
+// TestGetProcessStatusErrors tests the errors returned for missing or malformed status files.
+func TestGetProcessStatusErrors(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"1/status": {Data: []byte("Name:\tinit\nVmRSS:\t12 MB\n")},
+		"2/stat":   {Data: []byte("2 (kthreadd) S 0 0 0 0 -1 0 0 0 0 0 0 0 0 0 20 0 1 0 2 0 0\n")},
+	})
+
+	var perr *ParseError
+	if _, err := fs.GetProcessStatus(1); !errors.As(err, &perr) || perr.File != "1/status" || perr.Field != "VmRSS" || perr.Line != 2 {
+		t.Errorf("GetProcessStatus(1) = %v; want ParseError for 1/status line 2 field VmRSS", err)
+	}
+
+	if _, err := fs.GetProcessStatus(2); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("GetProcessStatus(2) = %v; want %v", err, ErrNotSupported)
+	}
+
+	if _, err := fs.GetProcessStatus(3); !errors.Is(err, ErrProcessGone) {
+		t.Errorf("GetProcessStatus(3) = %v; want %v", err, ErrProcessGone)
+	}
+}
+
+// TestGetProcessStatusLive tests reading the status of the running process.
+func TestGetProcessStatusLive(t *testing.T) {
+	p, err := GetProcessStatus(os.Getpid())
+	if err != nil {
+		t.Fatalf("GetProcessStatus(%v): %v", os.Getpid(), err)
+	}
+
+	if p.Pid != os.Getpid() || p.UIDs[0] != uint32(os.Getuid()) || p.VmRSS == 0 || len(p.CpusAllowed) == 0 {
+		t.Errorf("GetProcessStatus(%v) = %+v", os.Getpid(), p)
+	}
+}
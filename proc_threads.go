@@ -0,0 +1,147 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+)
+
+const procdir_per_process_task string = "task"
+
+// ProcThread contains a thread of a process, available in /proc/<pid>/task/<tid>.
+type ProcThread struct {
+	Procstat // Pid is the thread ID, and the CPU times are those of the thread
+
+	// Status is the status of the thread, or nil if it was not requested.
+	Status *ProcStatus
+}
+
+// GetProcessThreads returns the threads of a giving process, in ascending thread ID order.
+func GetProcessThreads(pid int) ([]ProcThread, error) {
+	return defaultFS.GetProcessThreads(pid)
+}
+
+// GetProcessThreads returns the threads of a giving process, in ascending thread ID order.
+// Threads that exit while they are being read are skipped.
+func (pfs FS) GetProcessThreads(pid int) ([]ProcThread, error) {
+	return pfs.processThreads(context.Background(), pid, false)
+}
+
+// GetProcessThreadsContext is like GetProcessThreads, but stops when ctx is done.
+func GetProcessThreadsContext(ctx context.Context, pid int) ([]ProcThread, error) {
+	return defaultFS.GetProcessThreadsContext(ctx, pid)
+}
+
+// GetProcessThreadsContext is like GetProcessThreads, but stops when ctx is done.
+// In that case, ctx.Err() is returned.
+func (pfs FS) GetProcessThreadsContext(ctx context.Context, pid int) ([]ProcThread, error) {
+	return pfs.processThreads(ctx, pid, false)
+}
+
+// GetProcessThreadsWithStatus is like GetProcessThreads, and also reads the status of every thread.
+func GetProcessThreadsWithStatus(pid int) ([]ProcThread, error) {
+	return defaultFS.GetProcessThreadsWithStatus(pid)
+}
+
+// GetProcessThreadsWithStatus is like GetProcessThreads, and also reads the status of every thread.
+func (pfs FS) GetProcessThreadsWithStatus(pid int) ([]ProcThread, error) {
+	return pfs.processThreads(context.Background(), pid, true)
+}
+
+// GetProcessThreadsWithStatusContext is like GetProcessThreadsWithStatus, but stops when ctx is done.
+func GetProcessThreadsWithStatusContext(ctx context.Context, pid int) ([]ProcThread, error) {
+	return defaultFS.GetProcessThreadsWithStatusContext(ctx, pid)
+}
+
+// GetProcessThreadsWithStatusContext is like GetProcessThreadsWithStatus, but stops when ctx is done.
+// In that case, ctx.Err() is returned.
+func (pfs FS) GetProcessThreadsWithStatusContext(ctx context.Context, pid int) ([]ProcThread, error) {
+	return pfs.processThreads(ctx, pid, true)
+}
+
+// processThreads reads the stat, and the status if withStatus is set, of the threads of process pid.
+// It stops with ctx.Err() when ctx is done.
+func (pfs FS) processThreads(ctx context.Context, pid int, withStatus bool) ([]ProcThread, error) {
+	p := strconv.Itoa(pid)
+
+	entries, err := pfs.readDir(p, procdir_per_process_task)
+	if err != nil {
+		return nil, err
+	}
+
+	threads := make([]ProcThread, 0, len(entries))
+
+	var goneErr error
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if !e.IsDir() || !isNumeric(e.Name()) {
+			continue
+		}
+
+		t, err := pfs.processThread(path.Join(p, procdir_per_process_task, e.Name()), withStatus)
+		if isProcessGone(err) || errors.Is(err, fs.ErrNotExist) {
+			// The thread has exited since the directory was read.
+			goneErr = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		threads = append(threads, t)
+	}
+
+	// A process has at least one thread, so if all of them are gone, so is
+	// the process.
+	if len(threads) == 0 && goneErr != nil {
+		if !isProcessGone(goneErr) {
+			goneErr = &readError{kind: ErrProcessGone, err: errors.Unwrap(goneErr)}
+		}
+		return nil, goneErr
+	}
+
+	sort.Slice(threads, func(i, j int) bool { return threads[i].Pid < threads[j].Pid })
+
+	return threads, nil
+}
+
+// processThread reads the thread in the task directory dir, e.g. "1/task/2".
+func (pfs FS) processThread(dir string, withStatus bool) (ProcThread, error) {
+	statFile := path.Join(dir, procdir_per_process_stat)
+
+	dat, err := pfs.readFile(statFile)
+	if err != nil {
+		return ProcThread{}, err
+	}
+
+	p, err := parseProcstat(statFile, dat)
+	if err != nil {
+		return ProcThread{}, err
+	}
+
+	t := ProcThread{Procstat: p}
+
+	if withStatus {
+		statusFile := path.Join(dir, procdir_per_process_status)
+
+		dat, err := pfs.readFile(statusFile)
+		if err != nil {
+			return ProcThread{}, err
+		}
+
+		s, err := parseProcStatus(statusFile, dat)
+		if err != nil {
+			return ProcThread{}, err
+		}
+		t.Status = &s
+	}
+
+	return t, nil
+}
@@ -0,0 +1,96 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+// testThreadsFS is a procfs fixture with a JVM whose third thread exited
+// while it was listed (1), a process whose threads all exited (5) and a
+// process with a malformed thread (7).
+var testThreadsFS = fstest.MapFS{
+	"1/stat":           {Data: []byte("1 (java) S 0 1 1 0 -1 4194560 100 0 0 0 1500 300 0 0 20 0 3 0 27 3221225472 3199 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"1/task/1/stat":    {Data: []byte("1 (java) S 0 1 1 0 -1 4194560 40 0 0 0 100 20 0 0 20 0 3 0 27 3221225472 3199 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"1/task/1/status":  {Data: []byte("Name:\tjava\nState:\tS (sleeping)\nPid:\t1\nvoluntary_ctxt_switches:\t10\n")},
+	"1/task/12/stat":   {Data: []byte("12 (C2 CompilerThre) R 0 1 1 0 -1 4194560 60 0 0 0 1400 280 0 0 20 0 3 0 30 3221225472 3199 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 1 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"1/task/12/status": {Data: []byte("Name:\tC2 CompilerThre\nState:\tR (running)\nPid:\t12\nvoluntary_ctxt_switches:\t3\n")},
+	"1/task/9/status":  {Data: []byte("Name:\tgone\n")},
+	"5/task/5/comm":    {Data: []byte("gone\n")},
+	"7/task/7/stat":    {Data: []byte("7 (bad) S x\n")},
+}
+
+// TestGetProcessThreads tests reading the threads of a process.
+func TestGetProcessThreads(t *testing.T) {
+	fs := NewFSFromFS(testThreadsFS)
+
+	threads, err := fs.GetProcessThreads(1)
+	if err != nil || len(threads) != 2 {
+		t.Fatalf("GetProcessThreads(1) = %+v, %v; want 2 threads", threads, err)
+	}
+
+	if threads[0].Pid != 1 || threads[1].Pid != 12 || threads[1].Comm != "C2 CompilerThre" || threads[1].Utime != 1400 || threads[1].Processor != 1 {
+		t.Errorf("GetProcessThreads(1) = %+v", threads)
+	}
+	if threads[0].Status != nil {
+		t.Errorf("GetProcessThreads(1) read the status: %+v", threads[0].Status)
+	}
+
+	threads, err = fs.GetProcessThreadsWithStatus(1)
+	if err != nil || len(threads) != 2 || threads[1].Status == nil || threads[1].Status.State != "R (running)" || threads[1].Status.VoluntaryCtxtSwitches != 3 {
+		t.Errorf("GetProcessThreadsWithStatus(1) = %+v, %v", threads, err)
+	}
+
+	if _, err := fs.GetProcessThreads(5); !errors.Is(err, ErrProcessGone) || errors.Is(err, ErrNotSupported) {
+		t.Errorf("GetProcessThreads(5) = %v; want %v", err, ErrProcessGone)
+	}
+
+	if _, err := fs.GetProcessThreads(6); !errors.Is(err, ErrProcessGone) {
+		t.Errorf("GetProcessThreads(6) = %v; want %v", err, ErrProcessGone)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if threads, err := fs.GetProcessThreadsWithStatusContext(ctx, 1); threads != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("GetProcessThreadsWithStatusContext() with a canceled ctx = %+v, %v; want %v", threads, err, context.Canceled)
+	}
+
+	var perr *ParseError
+	if _, err := fs.GetProcessThreads(7); !errors.As(err, &perr) || perr.File != "7/task/7/stat" {
+		t.Errorf("GetProcessThreads(7) = %v; want ParseError for 7/task/7/stat", err)
+	}
+}
+
+// TestGetPerProcessStatThreads tests that the per-process scan reads the
+// threads only when asked to.
+func TestGetPerProcessStatThreads(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"1/stat":         testThreadsFS["1/stat"],
+		"1/task/1/stat":  testThreadsFS["1/task/1/stat"],
+		"1/task/12/stat": testThreadsFS["1/task/12/stat"],
+	})
+
+	pps, err := fs.GetPerProcessStatWithOptions(StatScanOptions{})
+	if err != nil || len(pps) != 1 || pps[0].Threads != nil {
+		t.Errorf("GetPerProcessStatWithOptions() = %+v, %v; want no threads", pps, err)
+	}
+
+	pps, err = fs.GetPerProcessStatWithOptions(StatScanOptions{ScanOptions: ScanOptions{Workers: 2}, Threads: true})
+	if err != nil || len(pps) != 1 || len(pps[0].Threads) != 2 || pps[0].Threads[1].Comm != "C2 CompilerThre" {
+		t.Errorf("GetPerProcessStatWithOptions(Threads) = %+v, %v", pps, err)
+	}
+}
+
+// TestGetProcessThreadsLive tests reading the threads of the running process.
+func TestGetProcessThreadsLive(t *testing.T) {
+	threads, err := GetProcessThreadsWithStatus(os.Getpid())
+	if err != nil || len(threads) == 0 {
+		t.Fatalf("GetProcessThreadsWithStatus(%v) = %+v, %v", os.Getpid(), threads, err)
+	}
+
+	if threads[0].Pid != os.Getpid() || threads[0].Status == nil || threads[0].Status.Tgid != os.Getpid() {
+		t.Errorf("GetProcessThreadsWithStatus(%v)[0] = %+v", os.Getpid(), threads[0])
+	}
+}
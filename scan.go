@@ -0,0 +1,198 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// PidError records a failure to read the information of a single process.
+type PidError struct {
+	Pid int
+	Err error
+}
+
+func (e PidError) Error() string {
+	return fmt.Sprintf("pid %v: %v", e.Pid, e.Err)
+}
+
+func (e PidError) Unwrap() error {
+	return e.Err
+}
+
+// ScanError is returned by the per-process scans when some processes could not be read.
+// The processes that were read are returned along with it.
+type ScanError struct {
+	Errors []PidError
+}
+
+func (e *ScanError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	return fmt.Sprintf("%v (and %v more errors)", e.Errors[0], len(e.Errors)-1)
+}
+
+// ScanOptions configures the per-process scans, e.g. GetPerProcessStatmContext.
+type ScanOptions struct {
+	// Workers is the number of processes read concurrently. Values below 2
+	// read the processes one at a time.
+	Workers int
+}
+
+// StatScanOptions configures the scans of GetPerProcessStatWithOptions and
+// GetPerProcessStatContext, and the samples of a ProcSampler.
+type StatScanOptions struct {
+	ScanOptions
+
+	// Cmdline makes the scan also read the command line of every process
+	// into Procstat.Cmdline.
+	Cmdline bool
+
+	// Threads makes the scan also read the stat of the threads of every
+	// process into Procstat.Threads.
+	Threads bool
+}
+
+// foundIndices returns the indices whose found flag is set, in ascending order.
+func foundIndices(found []bool) []int {
+	var idx []int
+	for i, ok := range found {
+		if ok {
+			idx = append(idx, i)
+		}
+	}
+
+	return idx
+}
+
+// newScanError returns a *ScanError for the non-nil errs, indexed like pids,
+// or nil if there are none.
+func newScanError(pids []int, errs []error) error {
+	var scanErr ScanError
+
+	for i, err := range errs {
+		if err != nil {
+			scanErr.Errors = append(scanErr.Errors, PidError{Pid: pids[i], Err: err})
+		}
+	}
+
+	if len(scanErr.Errors) == 0 {
+		return nil
+	}
+
+	return &scanErr
+}
+
+// scan calls read for every index in [0, n) using up to workers goroutines,
+// and passes each successful result to collect from the calling goroutine.
+// It returns the errors indexed like the calls; errors of processes that
+// exited are dropped.
+//
+// If ctx is done before every call returned, scan returns ctx.Err() without
+// waiting for the reads in progress, which may be stuck on an unresponsive
+// file; their results are discarded.
+func scan(ctx context.Context, n int, workers int, read func(i int) (interface{}, error), collect func(i int, v interface{})) ([]error, error) {
+	type result struct {
+		i   int
+		v   interface{}
+		err error
+	}
+
+	errs := make([]error, n)
+
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	next := make(chan int)
+	defer close(next)
+
+	// Buffered so that abandoned workers never block.
+	results := make(chan result, n)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range next {
+				v, err := read(i)
+				results <- result{i: i, v: v, err: err}
+			}
+		}()
+	}
+
+	for sent, received := 0, 0; received < n; {
+		var send chan<- int
+		if sent < n {
+			send = next
+		}
+
+		select {
+		case send <- sent:
+			sent++
+		case r := <-results:
+			received++
+			if r.err == nil {
+				collect(r.i, r.v)
+			} else if !isProcessGone(r.err) {
+				errs[r.i] = r.err
+			}
+		case <-ctx.Done():
+			return errs, ctx.Err()
+		}
+	}
+
+	return errs, nil
+}
+
+// pids returns the PIDs of all processes, in ascending order.
+func (pfs FS) pids() ([]int, error) {
+	files, err := pfs.readDir(".")
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+
+	for _, f := range files {
+		if !f.IsDir() || !isNumeric(f.Name()) {
+			continue
+		}
+
+		pid, err := strconv.Atoi(f.Name())
+		if err != nil {
+			continue
+		}
+
+		pids = append(pids, pid)
+	}
+
+	sort.Ints(pids)
+
+	return pids, nil
+}
+
+// isNumeric reports whether s is a non-empty string of decimal digits.
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isProcessGone reports whether err was caused by a process that exited.
+func isProcessGone(err error) bool {
+	return errors.Is(err, ErrProcessGone)
+}
@@ -0,0 +1,157 @@
+package lpfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// testScanFS is a procfs fixture with PIDs that do not sort lexically,
+// non-PID directories, a malformed process and a process that has exited.
+var testScanFS = fstest.MapFS{
+	"1/stat":      {Data: []byte("1 (systemd) S 0 1 1 0 -1 4194560 46427 3183421 114 1180 122 285 11463 2669 20 0 1 0 27 172404736 3199 18446744073709551615 1 1 0 0 0 0 671173123 4096 1260 0 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"2/stat":      {Data: []byte("2 (kthreadd) S 0 0 0 0 -1 2129984 0 0 0 0 0 0 0 0 20 0 1 0 5 0 0 18446744073709551615 0 0 0 0 0 0 0 2147483647 0 1 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"10/stat":     {Data: []byte("10 (kworker/0:0H-events_highpri) I 2 0 0 0 -1 69238880 0 0 0 0 0 0 0 0 0 -20 1 0 5 0 0 18446744073709551615 0 0 0 0 0 0 0 2147483647 0 1 0 0 17 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n")},
+	"100/stat":    {Data: []byte("garbage\n")},
+	"200/status":  {Data: []byte("Name:\tgone\n")},
+	"12345":       {Data: []byte("not a directory\n")},
+	"self/stat":   {Data: []byte("1 (systemd) S 0\n")},
+	"acpi/wakeup": {Data: []byte("\n")},
+	"zoneinfo":    {Data: []byte("\n")},
+}
+
+// vanishedFS hides the files of a process that exited after the procfs root was listed.
+type vanishedFS struct {
+	fs.FS
+	pid string
+}
+
+func (v vanishedFS) Open(name string) (fs.File, error) {
+	if name == v.pid || strings.HasPrefix(name, v.pid+"/") {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return v.FS.Open(name)
+}
+
+// newTestScanFS returns a FS of testScanFS in which process 200 has exited.
+func newTestScanFS() FS {
+	return NewFSFromFS(vanishedFS{FS: testScanFS, pid: "200"})
+}
+
+// TestPids tests the enumeration of PIDs in the procfs root.
+func TestPids(t *testing.T) {
+	pids, err := NewFSFromFS(testScanFS).pids()
+	if err != nil {
+		t.Fatalf("pids(): %v", err)
+	}
+
+	want := []int{1, 2, 10, 100, 200}
+	if len(pids) != len(want) {
+		t.Fatalf("pids() = %v; want %v", pids, want)
+	}
+	for i := range want {
+		if pids[i] != want[i] {
+			t.Fatalf("pids() = %v; want %v", pids, want)
+		}
+	}
+}
+
+// TestGetPerProcessStatPartial tests that GetPerProcessStat skips exited
+// processes and reports the others that failed in a ScanError.
+func TestGetPerProcessStatPartial(t *testing.T) {
+	pps, err := newTestScanFS().GetPerProcessStat()
+
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("GetPerProcessStat() error = %v; want a *ScanError", err)
+	}
+	if len(scanErr.Errors) != 1 || scanErr.Errors[0].Pid != 100 {
+		t.Errorf("GetPerProcessStat() errors = %v; want only PID 100", scanErr.Errors)
+	}
+
+	if len(pps) != 3 || pps[0].Pid != 1 || pps[1].Pid != 2 || pps[2].Pid != 10 {
+		t.Errorf("GetPerProcessStat() = %v; want PIDs 1, 2 and 10", pps)
+	}
+}
+
+// TestGetPerProcessStatWorkers tests that a concurrent scan returns the same
+// result, in the same order, as a serial one.
+func TestGetPerProcessStatWorkers(t *testing.T) {
+	fs := newTestScanFS()
+
+	serial, serialErr := fs.GetPerProcessStat()
+
+	for _, workers := range []int{2, 4, 64} {
+		pps, err := fs.GetPerProcessStatWithOptions(StatScanOptions{ScanOptions: ScanOptions{Workers: workers}})
+		if !reflect.DeepEqual(pps, serial) {
+			t.Errorf("Workers: %v: GetPerProcessStatWithOptions() = %v; want %v", workers, pps, serial)
+		}
+		if !reflect.DeepEqual(err, serialErr) {
+			t.Errorf("Workers: %v: GetPerProcessStatWithOptions() error = %v; want %v", workers, err, serialErr)
+		}
+	}
+}
+
+// BenchmarkGetPerProcessStat compares serial and concurrent scans of the live /proc.
+func BenchmarkGetPerProcessStat(b *testing.B) {
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("Workers=%v", workers), func(b *testing.B) {
+			opts := StatScanOptions{ScanOptions: ScanOptions{Workers: workers}}
+			for i := 0; i < b.N; i++ {
+				if _, err := GetPerProcessStatWithOptions(opts); err != nil {
+					b.Fatalf("%v", err)
+				}
+			}
+		})
+	}
+}
+
+// stuckFS is a fs.FS whose files under a given directory block on Open until release is closed.
+type stuckFS struct {
+	fs.FS
+	dir     string
+	release chan struct{}
+}
+
+func (s stuckFS) Open(name string) (fs.File, error) {
+	if strings.HasPrefix(name, s.dir+"/") {
+		<-s.release
+	}
+
+	return s.FS.Open(name)
+}
+
+// TestGetPerProcessStatContext tests that a scan stops when its context is
+// done, even if a read is stuck, and returns the processes read so far.
+func TestGetPerProcessStatContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pps, err := newTestScanFS().GetPerProcessStatContext(ctx, StatScanOptions{})
+	if !errors.Is(err, context.Canceled) || len(pps) != 0 {
+		t.Errorf("GetPerProcessStatContext() with a canceled context = %v, %v", pps, err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+
+	fs := NewFSFromFS(stuckFS{FS: vanishedFS{FS: testScanFS, pid: "200"}, dir: "10", release: release})
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	pps, err = fs.GetPerProcessStatContext(ctx, StatScanOptions{ScanOptions: ScanOptions{Workers: 1}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetPerProcessStatContext() error = %v; want context.DeadlineExceeded", err)
+	}
+	if len(pps) != 2 || pps[0].Pid != 1 || pps[1].Pid != 2 {
+		t.Errorf("GetPerProcessStatContext() = %v; want PIDs 1 and 2", pps)
+	}
+}
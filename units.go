@@ -0,0 +1,154 @@
+package lpfs
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+const (
+	procdir_auxv string = "self/auxv"
+
+	// userHZ is the fallback number of clock ticks per second (USER_HZ),
+	// which is 100 on all mainstream architectures.
+	userHZ = 100
+
+	// Auxiliary vector entry types, see getauxval(3).
+	atNull   = 0
+	atPagesz = 6
+	atClktck = 17
+)
+
+// Units contains the system constants needed to convert procfs values to real units.
+type Units struct {
+	ClockTicks uint64    // clock ticks per second (USER_HZ), e.g. of Procstat.Utime
+	PageSize   uint64    // page size in bytes, e.g. of Procstat.Rss
+	BootTime   time.Time // time the system booted, e.g. for Procstat.Starttime
+}
+
+// nativeEndian is the byte order of the auxiliary vector.
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// GetUnits returns the system constants needed to convert procfs values to real units.
+func GetUnits() (Units, error) {
+	return defaultFS.GetUnits()
+}
+
+// GetUnits returns the system constants needed to convert procfs values to real units.
+// ClockTicks and PageSize come from /proc/self/auxv, falling back to 100 and
+// the page size of the calling process, and BootTime from /proc/stat.
+func (pfs FS) GetUnits() (Units, error) {
+	u := pfs.auxvUnits()
+
+	s, err := pfs.GetStat()
+	if err != nil {
+		return Units{}, err
+	}
+
+	u.BootTime = time.Unix(int64(s.Btime), 0)
+
+	return u, nil
+}
+
+// auxvCache holds the Units read from /proc/self/auxv, which do not change
+// while the system runs, so that they are read once per FS.
+type auxvCache struct {
+	once  sync.Once
+	units Units
+}
+
+// auxvUnits returns the Units with ClockTicks and PageSize read from
+// /proc/self/auxv, or their fallback values if it cannot be read.
+func (pfs FS) auxvUnits() Units {
+	if pfs.fsys == nil {
+		return defaultFS.auxvUnits()
+	}
+	if pfs.auxv == nil {
+		return pfs.readAuxvUnits()
+	}
+
+	pfs.auxv.once.Do(func() {
+		pfs.auxv.units = pfs.readAuxvUnits()
+	})
+
+	return pfs.auxv.units
+}
+
+// readAuxvUnits reads the Units of auxvUnits.
+func (pfs FS) readAuxvUnits() Units {
+	u := Units{ClockTicks: userHZ, PageSize: uint64(os.Getpagesize())}
+
+	dat, err := pfs.readFile(procdir_auxv)
+	if err != nil {
+		return u
+	}
+
+	if v, ok := auxv(dat, atClktck); ok && v > 0 {
+		u.ClockTicks = v
+	}
+	if v, ok := auxv(dat, atPagesz); ok && v > 0 {
+		u.PageSize = v
+	}
+
+	return u
+}
+
+// auxv returns the value of the entry of type typ in the auxiliary vector dat,
+// made of pairs of native words.
+func auxv(dat []byte, typ uint64) (uint64, bool) {
+	word := strconv.IntSize / 8
+
+	for i := 0; i+2*word <= len(dat); i += 2 * word {
+		var k, v uint64
+		if word == 8 {
+			k = nativeEndian.Uint64(dat[i:])
+			v = nativeEndian.Uint64(dat[i+word:])
+		} else {
+			k = uint64(nativeEndian.Uint32(dat[i:]))
+			v = uint64(nativeEndian.Uint32(dat[i+word:]))
+		}
+
+		if k == atNull {
+			break
+		}
+		if k == typ {
+			return v, true
+		}
+	}
+
+	return 0, false
+}
+
+// Duration converts ticks clock ticks to a time.Duration.
+func (u Units) Duration(ticks uint64) time.Duration {
+	hz := u.ClockTicks
+	if hz == 0 {
+		hz = userHZ
+	}
+
+	return time.Duration(ticks/hz)*time.Second + time.Duration(ticks%hz)*time.Second/time.Duration(hz)
+}
+
+// CPUTime returns the time the process spent in user and system mode.
+func (p Procstat) CPUTime(u Units) time.Duration {
+	return u.Duration(p.Utime + p.Stime)
+}
+
+// StartTime returns the time the process started.
+func (p Procstat) StartTime(u Units) time.Time {
+	return u.BootTime.Add(u.Duration(p.Starttime))
+}
+
+// RSSBytes returns the resident set size of the process in bytes.
+func (p Procstat) RSSBytes(u Units) uint64 {
+	return p.Rss * u.PageSize
+}
@@ -0,0 +1,77 @@
+package lpfs
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// makeAuxv returns an auxiliary vector of native words made of the given type/value pairs.
+func makeAuxv(pairs ...uint64) []byte {
+	word := strconv.IntSize / 8
+	dat := make([]byte, 0, (len(pairs)+2)*word)
+
+	for _, v := range append(pairs, atNull, 0) {
+		b := make([]byte, word)
+		if word == 8 {
+			nativeEndian.PutUint64(b, v)
+		} else {
+			nativeEndian.PutUint32(b, uint32(v))
+		}
+		dat = append(dat, b...)
+	}
+
+	return dat
+}
+
+// TestGetUnits tests the detection of the clock ticks, page size and boot time.
+func TestGetUnits(t *testing.T) {
+	fs := NewFSFromFS(fstest.MapFS{
+		"self/auxv": {Data: makeAuxv(33, 140359784046592, atPagesz, 16384, atClktck, 250, 3, 94471790776384)},
+		"stat":      {Data: []byte("cpu  1 2 3 4\nbtime 1700000000\n")},
+	})
+
+	u, err := fs.GetUnits()
+	want := Units{ClockTicks: 250, PageSize: 16384, BootTime: time.Unix(1700000000, 0)}
+	if err != nil || u != want {
+		t.Errorf("GetUnits() = %+v, %v; want %+v", u, err, want)
+	}
+
+	p := Procstat{Utime: 122, Stime: 285, Starttime: 27, Rss: 3199}
+
+	if d := p.CPUTime(u); d != 1628*time.Millisecond {
+		t.Errorf("CPUTime() = %v; want 1.628s", d)
+	}
+
+	if st := p.StartTime(u); !st.Equal(time.Unix(1700000000, 108000000)) {
+		t.Errorf("StartTime() = %v; want 108ms after boot", st)
+	}
+
+	if b := p.RSSBytes(u); b != 3199*16384 {
+		t.Errorf("RSSBytes() = %v; want %v", b, 3199*16384)
+	}
+
+	// Without /proc/self/auxv, the usual defaults are used.
+	u, err = NewFSFromFS(fstest.MapFS{"stat": {Data: []byte("btime 1700000000\n")}}).GetUnits()
+	if err != nil || u.ClockTicks != 100 || u.PageSize != uint64(os.Getpagesize()) {
+		t.Errorf("GetUnits() without auxv = %+v, %v", u, err)
+	}
+
+	if d := (Units{}).Duration(150); d != 1500*time.Millisecond {
+		t.Errorf("Duration() with unknown clock ticks = %v; want 1.5s", d)
+	}
+}
+
+// TestGetUnitsLive tests reading the units of the live /proc.
+func TestGetUnitsLive(t *testing.T) {
+	u, err := GetUnits()
+	if err != nil {
+		t.Fatalf("GetUnits(): %v", err)
+	}
+
+	if u.ClockTicks == 0 || u.PageSize != uint64(os.Getpagesize()) || u.BootTime.After(time.Now()) {
+		t.Errorf("GetUnits() = %+v", u)
+	}
+}